@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+command is a subcommand declared with the `cmd` struct tag.
+
+Its settings are scanned below node, and are given to the command line flag
+loader only when the command is selected.
+*/
+type command struct {
+	name  string
+	help  string
+	node  *NodePath
+	ptr   [2]reflect.Value // A nil pointer to the struct, and a temporary value.
+	flags *FlagLoader
+}
+
+// contains returns whether path is one of the settings of cmd.
+func (cmd *command) contains(path *Path) bool {
+	for np := path.parent; np != nil; np = np.parent {
+		if np == cmd.node {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+scanCommand scans a struct field with a `cmd` tag as the settings of a new
+command, named by the tag.
+
+It panics if the field is not a struct or pointer to a struct, if the name is
+already used by a command or by the prefix of another setting, or if np, the
+prefix of the field, is not the root, as commands can not be nested.
+*/
+func (c *Config) scanCommand(field reflect.StructField, val reflect.Value, np *NodePath) error {
+	name := field.Tag.Get("cmd")
+	if np != &c.root {
+		panic(fmt.Sprintf("command %s must be declared at the top level", name))
+	}
+	if c.root.find(name) != (child{}) {
+		panic(fmt.Sprintf("duplicate command name %s", name))
+	}
+	cmd := &command{name: name, help: field.Tag.Get("help")}
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() == reflect.Ptr {
+		t := val.Type().Elem()
+		for ; t.Kind() == reflect.Ptr; t = t.Elem() {
+		}
+		if t.Kind() == reflect.Struct {
+			cmd.ptr = [2]reflect.Value{val, reflect.New(t).Elem()}
+			val = cmd.ptr[1]
+		}
+	}
+	if val.Kind() != reflect.Struct {
+		panic(fmt.Sprintf(
+			"command %s must be a struct or pointer to a struct, not %s",
+			name, field.Type,
+		))
+	}
+	cmd.node = c.root.AddNodePath(c.root.NewNodePath(name))
+	c.commands = append(c.commands, cmd)
+	return c.scan(val, cmd.node)
+}
+
+/*
+commandSettings separates settings into those of each command, in the order
+of c.commands, and the remaining global settings.
+*/
+func (c *Config) commandSettings(settings []Setting) ([]Setting, [][]Setting) {
+	global := make([]Setting, 0, len(settings))
+	cmds := make([][]Setting, len(c.commands))
+	for i := range settings {
+		found := false
+		for j := range c.commands {
+			if c.commands[j].contains(settings[i].Path) {
+				cmds[j] = append(cmds[j], settings[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			global = append(global, settings[i])
+		}
+	}
+	return global, cmds
+}
+
+/*
+initCommands initializes a FlagLoader for each command, using the same options
+as fl, with the given settings.
+
+Flag names are generated as if the settings of each command were not nested
+below the command's name.
+*/
+func (c *Config) initCommands(fl *FlagLoader, settings [][]Setting) {
+	c.flags = fl
+	names := fl.Names
+	if names == nil {
+		names = FlagNames
+	}
+	commandNames := NameTransformerFunc(func(path *Path) string {
+		elements := path.Elements()[1:]
+		np := NewRootPath("")
+		for _, element := range elements[:len(elements)-1] {
+			np = np.NewNodePath(element)
+		}
+		return names.TransformName(np.NewPath(elements[len(elements)-1]))
+	})
+	for i, cmd := range c.commands {
+		cmd.flags = &FlagLoader{
+			Names:       commandNames,
+			ProgramName: fl.fs.Name() + " " + cmd.name,
+			GNU:         fl.GNU,
+			title:       "Command Line Flags for " + cmd.name,
+		}
+		cmd.flags.Init(settings[i])
+		cmd.flags.SetUsageFn(func() { c.Usage(nil) })
+	}
+}
+
+/*
+loadCommand selects the command named by the first argument left after
+parsing global flags, if any, and parses the remaining arguments as its flags.
+*/
+func (c *Config) loadCommand() error {
+	c.command = nil
+	args := c.flags.Args()
+	if len(args) == 0 {
+		return nil
+	}
+	for _, cmd := range c.commands {
+		if cmd.name == args[0] {
+			c.command = cmd
+			return cmd.flags.Parse(args[1:])
+		}
+	}
+	return nil
+}
+
+// commandUsage returns a string with a list of commands and their flags.
+func (c *Config) commandUsage() string {
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, cmd := range c.commands {
+		b.WriteString("  ")
+		b.WriteString(cmd.name)
+		if cmd.help != "" {
+			b.WriteString("\n    \t")
+			b.WriteString(strings.Replace(cmd.help, "\n", "\n    \t", -1))
+		}
+		b.WriteString("\n")
+	}
+	for _, cmd := range c.commands {
+		if cmd.flags != nil {
+			b.WriteString("\n")
+			b.WriteString(cmd.flags.Usage())
+		}
+	}
+	return b.String()
+}
@@ -22,6 +22,9 @@ type Config struct {
 	ptrs     [][2]reflect.Value
 	loaders  Loaders
 	reg      SetterRegistry
+	commands []*command
+	command  *command
+	flags    *FlagLoader
 }
 
 /*
@@ -92,10 +95,16 @@ func (c *Config) AddLoader(loader Loader) {
 /*
 Args returns the command-line arguments left after parsing.
 
+If a command was selected, these are the arguments left after parsing the
+command's flags, and do not include the command's name.
+
 Returns nil if Load has not been called, or if no flag loader was included in
 the config.
 */
 func (c *Config) Args() []string {
+	if c.command != nil {
+		return c.command.flags.Args()
+	}
 	// We only return something useful after Load() has been called, which means
 	// c.loaders should be populated.
 	for i := range c.loaders {
@@ -106,6 +115,20 @@ func (c *Config) Args() []string {
 	return nil
 }
 
+/*
+Command returns the name of the command selected by the command line
+arguments, as declared with the `cmd` struct tag.
+
+It returns the empty string if Load has not been called, or if no command was
+selected.
+*/
+func (c *Config) Command() string {
+	if c.command == nil {
+		return ""
+	}
+	return c.command.name
+}
+
 /*
 Scan uses reflection to populate configuration settings from a struct.
 
@@ -226,28 +249,90 @@ c.Scan or c.Var has been called to populate a list of values to set.
 
 Any validation or load errors will result in a non-nil return status.
 
+If any settings have the `bootstrap:"true"` tag, loading happens in two phases.
+In the first phase, only those loaders configured for a bootstrap setting are
+loaded, and only bootstrap settings are set. In the second phase, all loaders
+are loaded, and all settings except bootstrap settings are set. This allows
+bootstrap settings to configure the loaders themselves, such as selecting the
+files loaded by a FileLoader. If the first phase returns an error, the second
+phase is skipped.
+
+If any commands have been declared with the `cmd` struct tag, the first
+*FlagLoader parses global flags up to the first argument that is not a flag.
+If that argument names a command, the command is selected, and the arguments
+that follow are parsed as the command's flags. The settings of a command are
+not set by the global flags of any *FlagLoader, but are set by other loaders
+whether or not the command is selected.
+
 If one of the loaders is a *FlagLoader and the -h flag has not been overridden,
 calling Load with "-h" in the application's command line arguments will cause
 a list of settings and their descriptions to be printed to stderr.
 */
 func (c *Config) Load() error {
 	loaders := c.GetLoaders()
+	var err error
+	if c.settings.hasBootstrap() {
+		err = c.load(loaders, true)
+	}
+	if err == nil {
+		err = c.load(loaders, false)
+	}
+	c.setPtrs()
+	return err
+}
+
+/*
+load initializes and loads loaders for one phase of c.Load.
+
+Settings which are not to be set in the phase are passed to loaders with a
+Setter that discards any values, so that loaders still recognize them. During
+the bootstrap phase, loaders without any bootstrap settings are initialized,
+so that they can provide usage information, but are not loaded.
+*/
+func (c *Config) load(loaders Loaders, bootstrap bool) error {
 	settingsMap := c.settingsByLoader(loaders)
-	var errs Errors
+	load := make([]bool, len(loaders))
+	c.flags = nil
 	for i := range loaders {
-		if settings := settingsMap[loaders[i].Name()]; len(settings) != 0 {
-			loaders[i].Init(settings)
-			if loader, ok := loaders[i].(interface{ SetUsageFn(func()) }); ok {
-				loader.SetUsageFn(func() { c.Usage(nil) })
+		// Loaders with the same name share their settings, so each is given
+		// a copy before any Setters are wrapped.
+		settings := append([]Setting(nil), settingsMap[loaders[i].Name()]...)
+		fl, isFlags := loaders[i].(*FlagLoader)
+		if len(settings) == 0 && !(isFlags && len(c.commands) != 0) {
+			continue
+		}
+		for j := range settings {
+			if settings[j].isBootstrap() != bootstrap {
+				settings[j].Setter = &discardSetter{settings[j].Setter}
+			} else {
+				load[i] = true
 			}
 		}
+		var commandSettings [][]Setting
+		if isFlags && len(c.commands) != 0 {
+			settings, commandSettings = c.commandSettings(settings)
+		}
+		loaders[i].Init(settings)
+		if loader, ok := loaders[i].(interface{ SetUsageFn(func()) }); ok {
+			loader.SetUsageFn(func() { c.Usage(nil) })
+		}
+		if commandSettings != nil && c.flags == nil {
+			c.initCommands(fl, commandSettings)
+		}
 	}
+	var errs Errors
 	for i := range loaders {
+		if bootstrap && !load[i] {
+			continue
+		}
 		if err := loaders[i].Load(); err != nil {
 			errs.Append(err)
+		} else if loaders[i] == c.flags {
+			if err := c.loadCommand(); err != nil {
+				errs.Append(err)
+			}
 		}
 	}
-	c.setPtrs()
 	return errs.AsError()
 }
 
@@ -265,6 +350,10 @@ func (c *Config) Usage(w io.Writer) {
 	for i := range loaders {
 		io.WriteString(w, loaders[i].Usage())
 		io.WriteString(w, "\n")
+		if loaders[i] == c.flags {
+			io.WriteString(w, c.commandUsage())
+			io.WriteString(w, "\n")
+		}
 	}
 }
 
@@ -298,6 +387,12 @@ func (c *Config) scan(structVal reflect.Value, lastPath *NodePath) error {
 		if !fieldVal.CanInterface() {
 			continue
 		}
+		if structField.Tag.Get("cmd") != "" {
+			if err := c.scanCommand(structField, fieldVal, lastPath); err != nil {
+				errs.Append(err)
+			}
+			continue
+		}
 		name := structField.Tag.Get("config")
 		if name == "" {
 			name = structField.Name
@@ -365,13 +460,23 @@ func (c *Config) setPtrs() {
 	for i := len(c.ptrs) - 1; i >= 0; i-- {
 		ptr, val := c.ptrs[i][0], c.ptrs[i][1]
 		if reflect.Zero(val.Type()).Interface() != val.Interface() {
-			for t := ptr.Type().Elem(); t.Kind() == reflect.Ptr; t = t.Elem() {
-				ptr.Set(reflect.New(t))
-				ptr = ptr.Elem()
-			}
-			ptr.Set(val.Addr())
+			setPtr(ptr, val)
 		}
 	}
+	// The pointer to a selected command's struct is set even if the struct
+	// is a zero value.
+	if c.command != nil && c.command.ptr[0].IsValid() {
+		setPtr(c.command.ptr[0], c.command.ptr[1])
+	}
+}
+
+// setPtr sets the nil pointer ptr, and any pointers it points to, to val.
+func setPtr(ptr, val reflect.Value) {
+	for t := ptr.Type().Elem(); t.Kind() == reflect.Ptr; t = t.Elem() {
+		ptr.Set(reflect.New(t))
+		ptr = ptr.Elem()
+	}
+	ptr.Set(val.Addr())
 }
 
 func (c *Config) findSetter(val reflect.Value, tag reflect.StructTag) Setter {
@@ -379,15 +484,22 @@ func (c *Config) findSetter(val reflect.Value, tag reflect.StructTag) Setter {
 	if reg == nil {
 		reg = DefaultSetterRegistry
 	}
-	return reg.GetSetter(val, tag)
+	if setter := reg.GetSetter(val, tag); setter != nil {
+		return setter
+	}
+	return newStructSliceSetter(val, tag, reg)
 }
 
 func (c *Config) settingsByLoader(loaders []Loader) map[string]settings {
 	m := make(map[string]settings, len(loaders))
-	loaderNames := make([]string, len(loaders))
+	loaderNames := make([]string, 0, len(loaders))
 	for i := range loaders {
-		loaderNames[i] = loaders[i].Name()
-		m[loaderNames[i]] = make(settings, 0, len(c.settings))
+		// Loader names need not be unique, but each name should only be
+		// given each setting once.
+		if name := loaders[i].Name(); m[name] == nil {
+			loaderNames = append(loaderNames, name)
+			m[name] = make(settings, 0, len(c.settings))
+		}
 	}
 	for i := range c.settings {
 		var keys []string
@@ -397,6 +509,11 @@ func (c *Config) settingsByLoader(loaders []Loader) map[string]settings {
 			keys = strings.Split(tag, ",")
 		}
 		for _, k := range keys {
+			// A struct tag with the loader's name and the value "-" hides
+			// the setting from that loader, e.g. `env:"-"`.
+			if c.settings[i].Tag.Get(k) == "-" {
+				continue
+			}
 			if settings, ok := m[k]; ok {
 				m[k] = append(settings, c.settings[i])
 			}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -34,6 +35,9 @@ func TestConfig(t *testing.T) {
 	t.Run("Var", testConfigVar)
 	t.Run("Scan", testConfigScan)
 	t.Run("from", testConfigFrom)
+	t.Run("bootstrap", testConfigBootstrap)
+	t.Run("loader names", testConfigLoaderNames)
+	t.Run("commands", testConfigCommands)
 }
 
 func testConfigVar(t *testing.T) {
@@ -242,3 +246,229 @@ func testConfigFrom(t *testing.T) {
 		t.Errorf(`did not skip value for from:"flag"`)
 	}
 }
+
+func testConfigBootstrap(t *testing.T) {
+	first, cleanup := writeTempFile(t, "first.json", `{"x": 1, "y": 1, "z": 1}`)
+	defer cleanup()
+	second, cleanup := writeTempFile(t, "second.json", `{"x": 2, "y": 2, "z": 2}`)
+	defer cleanup()
+
+	var e env
+	defer e.Restore()
+	e.Set("Y", "3")
+
+	var c Config
+	files := &FileLoader{Filenames: []string{first}}
+	c.SetLoaders(Loaders{
+		files,
+		new(EnvLoader),
+		&FlagLoader{Arguments: []string{"-config", second, "-z", "4", "-v"}},
+	})
+	c.Var(&files.Filenames, `bootstrap:"true" from:"env,flag" append:"false"`, "config")
+	var x, y, z int
+	var verbose bool
+	c.Var(&x, "", "x")
+	c.Var(&y, "", "y")
+	c.Var(&z, "", "z")
+	c.Var(&verbose, `from:"flag"`, "v")
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed loading config: %s", err)
+	}
+	if len(files.Filenames) != 1 || files.Filenames[0] != second {
+		t.Errorf("unexpected files %s", files.Filenames)
+	}
+	if x != 2 || y != 3 || z != 4 || !verbose {
+		t.Errorf("unexpected values %d, %d, %d, %t", x, y, z, verbose)
+	}
+
+	t.Run("error", func(t *testing.T) {
+		var e env
+		defer e.Restore()
+		e.Set("LEVEL", "high")
+		var c Config
+		c.SetLoaders(Loaders{new(EnvLoader), new(ninetyNineLoader)})
+		var level, x int
+		c.Var(&level, `bootstrap:"true" from:"env"`, "level")
+		c.Var(&x, "", "x")
+		if err := c.Load(); err == nil {
+			t.Error("invalid bootstrap value did not return an error")
+		}
+		if x != 0 {
+			t.Errorf("value %d was set after bootstrap error", x)
+		}
+	})
+
+	t.Run("shared loader name", func(t *testing.T) {
+		dotenv, cleanup := writeTempFile(t, ".env", "X=5\n")
+		defer cleanup()
+		var c Config
+		env := &EnvLoader{Lookup: EnvironLookup([]string{"CONFIG=" + first})}
+		c.SetLoaders(Loaders{
+			files,
+			&DotEnvLoader{Filename: dotenv},
+			env,
+			&FlagLoader{Arguments: []string{}},
+		})
+		c.Var(&files.Filenames, `bootstrap:"true" from:"env,flag" append:"false"`, "config")
+		var x int
+		c.Var(&x, "", "x")
+		if err := c.Load(); err != nil {
+			t.Fatalf("failed loading config: %s", err)
+		}
+		if x != 5 || len(files.Filenames) != 1 || files.Filenames[0] != first {
+			t.Errorf("unexpected values %d, %s", x, files.Filenames)
+		}
+		usage := env.Usage()
+		for _, s := range []string{"CONFIG=", "X="} {
+			if !strings.Contains(usage, s) {
+				t.Errorf("usage %q does not contain %q", usage, s)
+			}
+		}
+	})
+}
+
+func testConfigLoaderNames(t *testing.T) {
+	var c Config
+	c.SetLoaders(Loaders{
+		&EnvLoader{
+			Prefix: "APP_",
+			Lookup: EnvironLookup([]string{
+				"DATABASE_URL=postgres://db", "APP_PASSWORD=secret", "PORT=80",
+			}),
+		},
+		&FlagLoader{Arguments: []string{"-db", "postgres://flag", "-port", "81"}},
+	})
+	opts := struct {
+		DatabaseURL string `env:"DATABASE_URL" flag:"db"`
+		Password    string `flag:"-"`
+		Port        int    `env:"-"`
+	}{}
+	if err := c.Configure(&opts); err != nil {
+		t.Fatalf("failed loading config: %s", err)
+	}
+	if opts.DatabaseURL != "postgres://flag" || opts.Password != "secret" || opts.Port != 81 {
+		t.Errorf("unexpected values %+v", opts)
+	}
+	if c.loaders[1].(*FlagLoader).fs.Lookup("password") != nil {
+		t.Error("flag was defined for hidden setting")
+	}
+}
+
+func testConfigCommands(t *testing.T) {
+	type options struct {
+		Verbose bool
+		Serve   struct {
+			Port int
+			TLS  struct {
+				Cert string
+			}
+		} `cmd:"serve" help:"Start the server."`
+		Migrate *struct {
+			DryRun bool
+		} `cmd:"migrate"`
+	}
+	load := func(args ...string) (*Config, *options, error) {
+		c := new(Config)
+		c.SetLoaders(Loaders{
+			&EnvLoader{Lookup: EnvironLookup([]string{"SERVE_PORT=80"})},
+			&FlagLoader{Arguments: args, ProgramName: "tool"},
+		})
+		opts := new(options)
+		return c, opts, c.Configure(opts)
+	}
+
+	t.Run("select", func(t *testing.T) {
+		c, opts, err := load("-verbose", "serve", "-port", "81", "-tls-cert", "c.pem", "extra")
+		if err != nil {
+			t.Fatalf("failed loading config: %s", err)
+		}
+		if cmd := c.Command(); cmd != "serve" {
+			t.Errorf("unexpected command %q", cmd)
+		}
+		if args := c.Args(); len(args) != 1 || args[0] != "extra" {
+			t.Errorf("unexpected args %q", args)
+		}
+		if !opts.Verbose || opts.Serve.Port != 81 || opts.Serve.TLS.Cert != "c.pem" {
+			t.Errorf("unexpected values %+v", opts)
+		}
+		if opts.Migrate != nil {
+			t.Errorf("unexpected value %+v for unselected command", opts.Migrate)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		c, opts, err := load("migrate")
+		if err != nil {
+			t.Fatalf("failed loading config: %s", err)
+		}
+		if cmd := c.Command(); cmd != "migrate" {
+			t.Errorf("unexpected command %q", cmd)
+		}
+		if opts.Migrate == nil || opts.Migrate.DryRun {
+			t.Errorf("unexpected value %+v for selected command", opts.Migrate)
+		}
+		if opts.Serve.Port != 80 {
+			t.Errorf("unexpected value %d for port", opts.Serve.Port)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		c, _, err := load("other")
+		if err != nil {
+			t.Fatalf("failed loading config: %s", err)
+		}
+		if cmd := c.Command(); cmd != "" {
+			t.Errorf("unexpected command %q", cmd)
+		}
+		if args := c.Args(); len(args) != 1 || args[0] != "other" {
+			t.Errorf("unexpected args %q", args)
+		}
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"-port", "81", "serve"},
+			{"serve", "-verbose"},
+			{"migrate", "-port", "81"},
+		} {
+			if _, _, err := load(args...); err == nil {
+				t.Errorf("no error loading args %s", args)
+			}
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		c, _, err := load("-verbose")
+		if err != nil {
+			t.Fatalf("failed loading config: %s", err)
+		}
+		var b strings.Builder
+		c.Usage(&b)
+		usage := b.String()
+		for _, s := range []string{
+			"Commands:\n  serve\n    \tStart the server.\n  migrate\n",
+			"Command Line Flags for serve:\n  -port int\n",
+			"  -tls-cert string\n",
+			"Command Line Flags for migrate:\n  -[no-]dry-run\n",
+			"SERVE_TLS_CERT=string",
+		} {
+			if !strings.Contains(usage, s) {
+				t.Errorf("usage %q does not contain %q", usage, s)
+			}
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("nested command did not panic")
+			}
+		}()
+		var opts struct {
+			Admin struct {
+				Serve struct{} `cmd:"serve"`
+			}
+		}
+		new(Config).Scan(&opts)
+	})
+}
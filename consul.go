@@ -0,0 +1,211 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+/*
+KVPair is a key and value read from a key/value store.
+*/
+type KVPair struct {
+	Key   string
+	Value []byte
+}
+
+/*
+KVClient is an interface for reading from a key/value store, such as Consul.
+*/
+type KVClient interface {
+	// List returns all pairs with keys beginning with prefix. It should not
+	// return an error if there are no such keys.
+	List(prefix string) ([]KVPair, error)
+}
+
+/*
+ConsulClient implements KVClient using the Consul KV HTTP API.
+
+Values are returned by the API encoded with base64, and are decoded by List.
+*/
+type ConsulClient struct {
+	// Address is the base URL of the Consul agent. If empty, the value of
+	// the CONSUL_HTTP_ADDR environment variable is used, or
+	// http://127.0.0.1:8500 if that is not set. If the URL has no scheme,
+	// http is assumed.
+	Address string
+	// Token is sent in the X-Consul-Token header if not empty. If empty, the
+	// value of the CONSUL_HTTP_TOKEN environment variable is used.
+	Token string
+	// Datacenter selects a datacenter other than that of the agent, if not
+	// empty.
+	Datacenter string
+	// Client is used to make requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Timeout limits the time taken by each request. If zero, a timeout of
+	// 10 seconds is used.
+	Timeout time.Duration
+}
+
+/*
+List returns all pairs with keys beginning with prefix.
+
+Keys with no value, such as those used as folders, are omitted.
+*/
+func (cc *ConsulClient) List(prefix string) ([]KVPair, error) {
+	addr := cc.Address
+	if addr == "" {
+		addr = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if addr == "" {
+		addr = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	query := url.Values{"recurse": {"true"}}
+	if cc.Datacenter != "" {
+		query.Set("dc", cc.Datacenter)
+	}
+	u := strings.TrimRight(addr, "/") + "/v1/kv/" +
+		(&url.URL{Path: strings.TrimLeft(prefix, "/")}).EscapedPath() +
+		"?" + query.Encode()
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	token := cc.Token
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	timeout := cc.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client := cc.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("consul: %s: %s", u, resp.Status)
+	}
+	var pairs []KVPair
+	// encoding/json decodes base64 strings into []byte values.
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, fmt.Errorf("consul: %s: %s", u, err)
+	}
+	n := 0
+	for i := range pairs {
+		if pairs[i].Value != nil {
+			pairs[n] = pairs[i]
+			n++
+		}
+	}
+	return pairs[:n], nil
+}
+
+/*
+ConsulLoader implements a Loader type to parse settings from a key/value store
+such as Consul.
+
+Keys below Prefix are split on "/", and correspond to the prefixes of nested
+structs and the names of individual settings, as described for FileLoader. For
+example, with Prefix "app",
+	opts := struct {
+		DB struct {
+			Host string
+		}
+		MaxConns int
+	}{}
+can be set from keys app/db/host and app/max_conns. Values are passed to
+Setter.Set, and keys that do not match any setting are ignored.
+
+The zero value is ready to use, and reads all keys from the Consul agent given
+by the CONSUL_HTTP_ADDR environment variable.
+*/
+type ConsulLoader struct {
+	// Prefix is the prefix of the keys to read, without a trailing "/".
+	Prefix string
+	// Client is used to read keys. If nil, a *ConsulClient with default
+	// settings is used.
+	Client   KVClient
+	settings []Setting
+}
+
+// Name returns the name of the loader. It is always "consul".
+func (*ConsulLoader) Name() string {
+	return "consul"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. It panics if two settings would be matched by
+the same key.
+*/
+func (cl *ConsulLoader) Init(settings []Setting) {
+	settings = stringSettings(settings)
+	newKeyIndex(settings)
+	cl.settings = settings
+}
+
+/*
+Load attempts to parse the configured settings from the keys below cl.Prefix.
+
+The returned error, if non-nil, will be of type Errors. Each element will be an
+error returned by the client, or of type *ConversionError or *ValidationError.
+*/
+func (cl *ConsulLoader) Load() error {
+	client := cl.Client
+	if client == nil {
+		client = new(ConsulClient)
+	}
+	prefix := strings.Trim(cl.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	pairs, err := client.List(prefix)
+	if err != nil {
+		return &Errors{err}
+	}
+	tree := make(map[string]interface{}, len(pairs))
+	for i := range pairs {
+		if !strings.HasPrefix(pairs[i].Key, prefix) {
+			continue
+		}
+		key := strings.Replace(pairs[i].Key[len(prefix):], "/", ".", -1)
+		tree[key] = string(pairs[i].Value)
+	}
+	return newKeyIndex(cl.settings).apply(tree)
+}
+
+// Usage returns a string with a list of keys and their descriptions.
+func (cl *ConsulLoader) Usage() string {
+	prefix := strings.Trim(cl.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	names := NameTransformerFunc(func(path *Path) string {
+		return prefix + strings.Replace(displayKey(path), ".", "/", -1)
+	})
+	return keyUsage("Consul Keys", cl.settings, names)
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeKVClient map[string]string
+
+func (f fakeKVClient) List(prefix string) ([]KVPair, error) {
+	if v, ok := f["error"]; ok {
+		return nil, errors.New(v)
+	}
+	var pairs []KVPair
+	for k, v := range f {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, KVPair{Key: k, Value: []byte(v)})
+		}
+	}
+	return pairs, nil
+}
+
+func TestConsulLoader(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("DB"))
+	var host string
+	var maxConns int
+	settings := settings{
+		{
+			Path: node.AddPath(node.NewPath("Host")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&host).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("MaxConns")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&maxConns).Elem(), `max:"100"`,
+			),
+		},
+	}
+
+	t.Run("load", func(t *testing.T) {
+		loader := &ConsulLoader{
+			Prefix: "app",
+			Client: fakeKVClient{
+				"app/db/host":   "db1",
+				"app/max-conns": "10",
+				"app/unknown":   "x",
+				"other/db/host": "db2",
+			},
+		}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if host != "db1" || maxConns != 10 {
+			t.Errorf("unexpected values %q, %d", host, maxConns)
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "  app/db/host=string\n") {
+			t.Errorf("unexpected usage %q", usage)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		loader := &ConsulLoader{Client: fakeKVClient{"max_conns": "101"}}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if ve, ok := (*errs)[0].(*ValidationError); !ok || ve.Path != settings[1].Path {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		loader := &ConsulLoader{Client: fakeKVClient{"error": "unavailable"}}
+		loader.Init(settings)
+		if err := loader.Load(); err == nil {
+			t.Error("client error was not returned")
+		}
+	})
+}
+
+func TestConsulClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("X-Consul-Token") != "token":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path != "/v1/kv/app/" || r.URL.Query().Get("recurse") != "true":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			fmt.Fprintf(
+				w, `[{"Key": "app/", "Value": null}, {"Key": "app/db/host", "Value": %q}]`,
+				base64.StdEncoding.EncodeToString([]byte("db1")),
+			)
+		}
+	}))
+	defer server.Close()
+
+	client := &ConsulClient{Address: server.URL, Token: "token"}
+	pairs, err := client.List("app/")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(pairs) != 1 || pairs[0].Key != "app/db/host" || string(pairs[0].Value) != "db1" {
+		t.Errorf("unexpected pairs %v", pairs)
+	}
+	if pairs, err := client.List("other/"); err != nil || len(pairs) != 0 {
+		t.Errorf("unexpected result %v, %v for missing prefix", pairs, err)
+	}
+	client.Token = "invalid"
+	if _, err := client.List("app/"); err == nil {
+		t.Error("forbidden request did not return an error")
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+	client = &ConsulClient{Address: slow.URL, Timeout: 10 * time.Millisecond}
+	if _, err := client.List("app/"); err == nil {
+		t.Error("slow request did not return an error")
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+)
+
+/*
+CredentialsLoader implements a Loader type to parse settings from systemd
+credentials.
+
+Services started by systemd with LoadCredential= or SetCredential= in their
+unit files find their credentials as files in the directory named by the
+CREDENTIALS_DIRECTORY environment variable. The name of each credential is
+matched to a setting, and its contents are the value, as described for
+DirLoader. For example, with
+	[Service]
+	LoadCredential=db-password:/etc/app/db-password
+the struct field
+	DBPassword string
+will be set to the contents of /etc/app/db-password. By default, credential
+names can be given as environment variable names, command line flag names or
+dotted names; setting Names selects a single naming scheme instead.
+
+If CREDENTIALS_DIRECTORY is not set, Load does nothing, so a CredentialsLoader
+can safely be included in the loaders of applications that are not always run
+by systemd.
+
+The zero value is ready to use.
+*/
+type CredentialsLoader struct {
+	// Names, if not nil, generates the credential name for each setting.
+	Names NameTransformer
+	dl    DirLoader
+}
+
+// Name returns the name of the loader. It is always "credentials".
+func (*CredentialsLoader) Name() string {
+	return "credentials"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. It panics if two settings would be matched by
+the same credential name.
+*/
+func (cl *CredentialsLoader) Init(settings []Setting) {
+	cl.dl.Names = cl.Names
+	cl.dl.Init(settings)
+}
+
+/*
+Load attempts to parse the configured settings from the files in
+$CREDENTIALS_DIRECTORY.
+
+The returned error, if non-nil, will be of type Errors, as described for
+DirLoader.Load.
+*/
+func (cl *CredentialsLoader) Load() error {
+	cl.dl.Dir = os.Getenv("CREDENTIALS_DIRECTORY")
+	return cl.dl.Load()
+}
+
+// Usage returns a string with a list of credential names and their
+// descriptions.
+func (cl *CredentialsLoader) Usage() string {
+	return keyUsage("systemd Credentials", cl.dl.settings, cl.Names)
+}
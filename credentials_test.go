@@ -0,0 +1,68 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCredentialsLoader(t *testing.T) {
+	root := NewRootPath("")
+	var password string
+	settings := settings{
+		{
+			Path: root.AddPath(root.NewPath("DBPassword")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&password).Elem(), "",
+			),
+		},
+	}
+	filename, cleanup := writeTempFile(t, "db-password", "secret\n")
+	defer cleanup()
+	var env env
+	defer env.Restore()
+
+	t.Run("unset", func(t *testing.T) {
+		env.Set("CREDENTIALS_DIRECTORY", "")
+		loader := new(CredentialsLoader)
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error %s", err)
+		}
+		if password != "" {
+			t.Errorf("unexpected value %q for password", password)
+		}
+	})
+
+	t.Run("load", func(t *testing.T) {
+		env.Set("CREDENTIALS_DIRECTORY", filepath.Dir(filename))
+		loader := new(CredentialsLoader)
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error %s", err)
+		}
+		if password != "secret" {
+			t.Errorf("unexpected value %q for password", password)
+		}
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		password = ""
+		loader := &CredentialsLoader{
+			Names: NameTransformerFunc(func(path *Path) string {
+				return "app." + DottedNames.TransformName(path)
+			}),
+		}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error %s", err)
+		}
+		if password != "" {
+			t.Errorf("unexpected value %q for password", password)
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "  app.db_password=string\n") {
+			t.Errorf("unexpected usage %q", usage)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+/*
+DirLoader implements a Loader type to parse settings from a directory of files,
+where the name of each file is the name of a setting, and the contents of the
+file are its value.
+
+This is the layout used for secrets mounted by Docker in /run/secrets, or for
+Kubernetes Secrets and ConfigMaps mounted as volumes. File names are matched to
+settings without regard to case, using the names of environment variables as
+generated by EnvLoader, command line flags as generated by FlagLoader, or
+dotted names as described for FileLoader. For example,
+	opts := struct {
+		Auth struct {
+			User string
+		}
+		DBPassword string
+	}{}
+can be set from files named either AUTH_USER, auth-user or auth.user, and
+either DB_PASSWORD, db-password or db_password.
+
+If Names is set, file names are instead matched to the names it generates,
+still without regard to case.
+
+Trailing newlines are trimmed from the contents of each file, and the result is
+passed to Setter.Set. Files that do not match any setting, and names starting
+with a dot, are ignored.
+
+The zero value is ready to use, but Load does nothing until Dir is set.
+*/
+type DirLoader struct {
+	// Dir is the directory to read.
+	Dir string
+	// Names, if not nil, generates the file name for each setting.
+	Names    NameTransformer
+	settings []Setting
+	names    map[string]int
+}
+
+// Name returns the name of the loader. It is always "dir".
+func (*DirLoader) Name() string {
+	return "dir"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. It panics if two settings would be matched by
+the same file name.
+*/
+func (dl *DirLoader) Init(settings []Setting) {
+	settings = stringSettings(settings)
+	dl.names = make(map[string]int, 3*len(settings))
+	for i := range settings {
+		path := settings[i].Path
+		names := []string{
+			EnvNames.TransformName(path),
+			FlagNames.TransformName(path),
+			DottedNames.TransformName(path),
+		}
+		if dl.Names != nil {
+			names = []string{dl.Names.TransformName(path)}
+		}
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if j, ok := dl.names[name]; ok && j != i {
+				panic(fmt.Sprintf(
+					"duplicate file name %s for %s and %s",
+					name, settings[j].Path, path,
+				))
+			}
+			dl.names[name] = i
+		}
+	}
+	dl.settings = settings
+}
+
+/*
+Load attempts to parse the configured settings from the files in dl.Dir.
+
+The returned error, if non-nil, will be of type Errors. Each element will be an
+error returned from reading the directory or a file, or a *FileError wrapping a
+*ConversionError or *ValidationError.
+*/
+func (dl *DirLoader) Load() error {
+	if dl.Dir == "" {
+		return nil
+	}
+	infos, err := ioutil.ReadDir(dl.Dir)
+	if err != nil {
+		return &Errors{err}
+	}
+	var errs Errors
+	for _, info := range infos {
+		name := info.Name()
+		i, ok := dl.names[strings.ToLower(name)]
+		if !ok || info.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		filename := filepath.Join(dl.Dir, name)
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			errs.Append(err)
+			continue
+		}
+		val := strings.TrimRight(string(data), "\r\n")
+		if err := dl.settings[i].Setter.Set(val); err != nil {
+			setErrorPath(err, dl.settings[i].Path)
+			errs.Append(&FileError{Filename: filename, Err: err})
+		}
+	}
+	return errs.AsError()
+}
+
+// Usage returns a string with a list of file names and their descriptions.
+func (dl *DirLoader) Usage() string {
+	return keyUsage("Files in Directory "+dl.Dir, dl.settings, dl.Names)
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirLoader(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("Auth"))
+	var user, password string
+	var hosts []string
+	var port int
+	settings := settings{
+		{
+			Path: node.AddPath(node.NewPath("User")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&user).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("DBPassword")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&password).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Hosts")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&hosts).Elem(), `sep:"\n"`,
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Port")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&port).Elem(), `max:"65535"`,
+			),
+		},
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "auth.user", "user1\n")
+		defer cleanup()
+		dir := filepath.Dir(filename)
+		for name, content := range map[string]string{
+			"DB_PASSWORD": "secret\n\n",
+			"hosts":       "a\nb\n",
+			".port":       "0",
+			"unknown":     "x",
+		} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+				t.Fatalf("failed writing %s: %s", name, err)
+			}
+		}
+		if err := os.Mkdir(filepath.Join(dir, "port"), 0700); err != nil {
+			t.Fatalf("failed creating directory: %s", err)
+		}
+		loader := &DirLoader{Dir: dir}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error loading %s: %s", dir, err)
+		}
+		if user != "user1" {
+			t.Errorf("unexpected value %q for user", user)
+		}
+		if password != "secret" {
+			t.Errorf("unexpected value %q for password", password)
+		}
+		if len(hosts) != 2 || hosts[0] != "a" || hosts[1] != "b" {
+			t.Errorf("unexpected value %q for hosts", hosts)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "port", "65536\n")
+		defer cleanup()
+		loader := &DirLoader{Dir: filepath.Dir(filename)}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		fe, ok := (*errs)[0].(*FileError)
+		if !ok || fe.Filename != filename {
+			t.Fatalf("unexpected error %s", (*errs)[0])
+		}
+		if ve, ok := fe.Err.(*ValidationError); !ok || ve.Path != settings[3].Path {
+			t.Errorf("unexpected error %s", fe.Err)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		loader := &DirLoader{Dir: "/nonexistent/secrets"}
+		loader.Init(settings)
+		if err := loader.Load(); err == nil {
+			t.Error("loading a missing directory did not return an error")
+		}
+	})
+}
@@ -80,6 +80,11 @@ The following types are supported by the package:
 In addition, any types derived from pointers and slices to those types are also
 supported.
 
+Slices of structs, or of pointers to structs, are supported by loaders for
+structured documents, such as FileLoader, where each table (or object) in an
+array appends a new element to the slice. They can not be set from strings, so
+loaders such as EnvLoader and FlagLoader ignore them.
+
 bool, float, int and uint values are parsed by strconv.ParseBool,
 strconv.ParseFloat, strconv.ParseInt and strconv.ParseUint. Trying to set a
 value that would overflow the type results in an error.
@@ -195,6 +200,54 @@ example
 	Interactive bool `from:"flag"`
 will only let the Interactive value be set from a command line flag.
 
+`env:"X"` and `flag:"X"` set the name of the environment variable or command
+line flag for a value, overriding the generated name for that loader only. The
+names are used exactly as given, and are not affected by EnvLoader.Prefix. A
+struct tag named after any loader with the value "-" hides the value from that
+loader. For example,
+	DatabaseURL string `env:"DATABASE_URL" flag:"db"`
+	Password    string `flag:"-"`
+lets DatabaseURL be set with DATABASE_URL or -db, and prevents Password from
+being set with a command line flag.
+
+`short:"X"` gives a single character alias for a command line flag, used when
+FlagLoader.GNU is set. For example,
+	Verbose bool `short:"v"`
+can then be set with -v as well as --verbose.
+
+`count:"true"` can be used with integer types to count the number of times a
+command line flag is given, without a value. For example,
+	Verbose int `short:"v" count:"true" max:"3"`
+is set to 2 by -v -v, or by -vv when FlagLoader.GNU is set. Validation tags
+apply to the count.
+
+`cmd:"X"` declares a struct field, which must be a struct or pointer to a
+struct, as the settings of a subcommand named X. Commands can only be declared
+in the struct passed to Config.Scan, and can not be nested. For example,
+	type Options struct {
+		Verbose bool
+		Serve   struct {
+			Port int
+		} `cmd:"serve" help:"Start the server."`
+	}
+accepts command lines such as "-verbose serve -port 80". The flags of a
+command follow its name, and are listed separately in usage. Other loaders set
+a command's settings as if nested below the command's name, as with
+SERVE_PORT. Config.Command returns the selected command, and a pointer to a
+command's struct is only set if the command is selected.
+
+`bootstrap:"true"` causes the value to be loaded before any other settings, so
+that it can be used to configure loaders. For example, to let the files loaded
+by a FileLoader be chosen with a -config flag or CONFIG environment variable:
+	files := &config.FileLoader{Filenames: []string{"/etc/app.json"}}
+	config.DefaultConfig.SetLoaders(config.Loaders{
+		files, new(config.EnvLoader), new(config.FlagLoader),
+	})
+	config.Var(&files.Filenames, `bootstrap:"true" from:"env,flag" append:"false"`, "config")
+	config.Configure(&opts)
+Files will then be loaded before environment variables, which in turn are
+loaded before command line flags. See Config.Load for details.
+
 `append:"false"` or `append:"true"` (the default) can be used with slice types.
 When true, values are appended to existing values in a slice. When false,
 setting a new value will cause the slice to be overwritten. (Setting multiple
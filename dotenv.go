@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+)
+
+/*
+DotEnvLoader implements a Loader type to parse settings from a .env file.
+
+Variable names are generated in exactly the same way as for EnvLoader, so a
+.env file can set any value that could be set by an environment variable. The
+name of the loader is also "env", so that settings restricted to environment
+variables with the `from:"env"` struct tag can be set from a .env file.
+
+Each line of the file contains a name and value separated by "=", optionally
+preceded by "export ". Blank lines and lines starting with "#" are ignored, as
+is anything following whitespace and "#" in an unquoted value:
+	# database settings
+	export DB_HOST=localhost   # the default
+	DB_PASSWORD='pa$$word'
+	DB_GREETING="Hello,\n\"World\""
+Values enclosed in single quotes are used as written, and may span multiple
+lines. Values in double quotes may also span multiple lines, and support the
+escape sequences \n, \r, \t, \", \$ and \\. As with EnvLoader, empty values are
+ignored.
+
+A DotEnvLoader is typically placed before an EnvLoader, so that variables set
+in the environment override those in the file:
+	config.Loaders{
+		&config.DotEnvLoader{Filename: ".env"},
+		new(config.EnvLoader),
+		new(config.FlagLoader),
+	}
+
+The embedded EnvLoader's fields, such as Prefix, are used to generate variable
+names, except for Lookup, which has no effect: values are only ever looked up
+in the file.
+
+The zero value is ready to use, but Load does nothing until Filename is set.
+*/
+type DotEnvLoader struct {
+	EnvLoader
+	// Filename is the name of the .env file to load.
+	Filename string
+}
+
+/*
+Load attempts to parse the configured settings from del.Filename.
+
+The returned error, if non-nil, will be an error returned from reading the
+file; a *FileError if the file could not be parsed; or of type Errors, in which
+case each element will be of type *ConversionError or *ValidationError.
+*/
+func (del *DotEnvLoader) Load() error {
+	if del.Filename == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(del.Filename)
+	if err != nil {
+		return err
+	}
+	vars, err := parseDotEnv(data)
+	if err != nil {
+		err.(*FileError).Filename = del.Filename
+		return err
+	}
+	return del.load(func(name string) string { return vars[name] })
+}
+
+/*
+Usage returns a string with a list of variable names and their descriptions.
+*/
+func (del *DotEnvLoader) Usage() string {
+	return del.usage("Environment File " + del.Filename)
+}
+
+/*
+parseDotEnv parses the contents of a .env file into a map of names to values.
+
+An error, if returned, will be of type *FileError, but without a file name.
+*/
+func parseDotEnv(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	lines := strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		// Only leading space is trimmed, as trailing space may be part of a
+		// quoted value spanning multiple lines.
+		line := strings.TrimLeft(lines[i], " \t")
+		if strings.TrimSpace(line) == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimLeft(line[len("export"):], " \t")
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, &FileError{Line: lineNo, Err: errors.New(`expected "=" after name`)}
+		}
+		name := strings.TrimSpace(line[:eq])
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return nil, &FileError{Line: lineNo, Err: errors.New("invalid variable name")}
+		}
+
+		value := strings.TrimLeft(line[eq+1:], " \t")
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			value = line[eq+1:]
+			for j := 1; j < len(value); j++ {
+				if value[j] == '#' && (value[j-1] == ' ' || value[j-1] == '\t') {
+					value = value[:j]
+					break
+				}
+			}
+			vars[name] = strings.TrimSpace(value)
+			continue
+		}
+
+		// Quoted values continue until the closing quote, which may be on a
+		// subsequent line.
+		quote, raw := value[0], value[1:]
+		for {
+			if end := closingQuote(raw, quote); end >= 0 {
+				if rest := strings.TrimSpace(raw[end+1:]); rest != "" && rest[0] != '#' {
+					return nil, &FileError{
+						Line: i + 1,
+						Err:  errors.New("unexpected characters after quoted value"),
+					}
+				}
+				raw = raw[:end]
+				break
+			}
+			if i++; i >= len(lines) {
+				return nil, &FileError{Line: lineNo, Err: errors.New("unterminated quoted value")}
+			}
+			raw += "\n" + lines[i]
+		}
+		if quote == '"' {
+			raw = unescapeDotEnv(raw)
+		}
+		vars[name] = raw
+	}
+	return vars, nil
+}
+
+// closingQuote returns the index of the unescaped quote that ends s, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+var dotEnvReplacer = strings.NewReplacer(
+	`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\$`, "$", `\\`, `\`,
+)
+
+// unescapeDotEnv replaces escape sequences in a double-quoted value.
+func unescapeDotEnv(s string) string {
+	return dotEnvReplacer.Replace(s)
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDotEnvLoader(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("DB"))
+	var host, password, greeting string
+	var port int
+	settings := settings{
+		{
+			Path: node.AddPath(node.NewPath("Greeting")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&greeting).Elem(), "",
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Host")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&host).Elem(), "",
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Password")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&password).Elem(), "",
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Port")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&port).Elem(), `max:"65535"`,
+			),
+		},
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, ".env", `
+# database settings
+export DB_HOST=localhost   # the default
+DB_PASSWORD='pa$$word # not a comment'
+DB_GREETING="Hello,\n\"World\"
+  and \$others" # a comment
+DB_PORT=
+`)
+		defer cleanup()
+		port = 5432
+		loader := &DotEnvLoader{Filename: filename}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error loading %s: %s", filename, err)
+		}
+		if host != "localhost" {
+			t.Errorf("unexpected value %q for host", host)
+		}
+		if password != "pa$$word # not a comment" {
+			t.Errorf("unexpected value %q for password", password)
+		}
+		if greeting != "Hello,\n\"World\"\n  and $others" {
+			t.Errorf("unexpected value %q for greeting", greeting)
+		}
+		if port != 5432 {
+			t.Errorf("unexpected value %d for port", port)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, ".env", "DB_HOST=localhost\nDB_PORT=1\n")
+		defer cleanup()
+		var env env
+		defer env.Restore()
+		env.Set("DB_HOST", "remotehost")
+		loaders := Loaders{&DotEnvLoader{Filename: filename}, new(EnvLoader)}
+		for i := range loaders {
+			loaders[i].Init(settings)
+			if err := loaders[i].Load(); err != nil {
+				t.Fatalf("unexpected error from %T: %s", loaders[i], err)
+			}
+		}
+		if host != "remotehost" {
+			t.Errorf("unexpected value %q for host", host)
+		}
+		if port != 1 {
+			t.Errorf("unexpected value %d for port", port)
+		}
+	})
+
+	t.Run("config", func(t *testing.T) {
+		// Both loaders are named "env", but should each set a value once.
+		filename, cleanup := writeTempFile(t, ".env", "DOTENV_LIST=a\n")
+		defer cleanup()
+		var c Config
+		c.SetLoaders(Loaders{&DotEnvLoader{Filename: filename}, new(EnvLoader)})
+		var list []string
+		c.Var(&list, "", "dotenv", "list")
+		if err := c.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if len(list) != 1 || list[0] != "a" {
+			t.Errorf("unexpected value %q for list", list)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, ".env", "DB_PORT=65536\n")
+		defer cleanup()
+		loader := &DotEnvLoader{Filename: filename}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		if ve, ok := (*errs)[0].(*ValidationError); !ok || ve.Path != settings[3].Path {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		for _, content := range []string{"\nDB_HOST\n", "\nDB_HOST='x\n", "\nDB HOST=x\n"} {
+			filename, cleanup := writeTempFile(t, ".env", content)
+			loader := &DotEnvLoader{Filename: filename}
+			loader.Init(settings)
+			err := loader.Load()
+			cleanup()
+			if fe, ok := err.(*FileError); !ok || fe.Line != 2 {
+				t.Errorf("unexpected error %v loading %q", err, content)
+			}
+		}
+	})
+}
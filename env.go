@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -28,9 +30,42 @@ finally joined with underscores to produce the final names:
 	HTML_PARSER_ELEMENT_IDS
 	HTML_PARSER_NAME
 
+If a variable is unset or empty, its value can instead be read from a file
+named by the same variable with a _FILE suffix, following the convention used
+for Docker secrets. For example, with
+	DBPassword string `envfile:"true"`
+if DB_PASSWORD is not set, the contents of the file named by DB_PASSWORD_FILE
+are used, with any trailing newlines trimmed. The _FILE variables can be
+enabled for all settings by setting FileVariables, in which case individual
+settings can opt out with `envfile:"false"`.
+
+A different naming scheme can be used by setting Names. If Prefix is set, it
+is prepended to each name. For example, with the prefix "MYAPP_", the names
+above become MYAPP_HTML_PARSER_ELEMENT_IDS and MYAPP_HTML_PARSER_NAME.
+
+The name of an individual setting can instead be given with the `env` struct
+tag, which is used as written, without Prefix:
+	DatabaseURL string `env:"DATABASE_URL"`
+
+Variables are read from the environment of the process unless Lookup is set.
+For example, to read variables from a list such as that of exec.Cmd.Env:
+	loader := &config.EnvLoader{Lookup: config.EnvironLookup(cmd.Env)}
+
 The zero value is ready to use.
 */
 type EnvLoader struct {
+	// Names generates the name of the variable for each setting. If nil,
+	// EnvNames is used.
+	Names NameTransformer
+	// Prefix is prepended to the name of each variable. It should include
+	// any separator, such as a trailing underscore.
+	Prefix string
+	// FileVariables enables _FILE variables for settings without an
+	// `envfile` struct tag.
+	FileVariables bool
+	// Lookup, if not nil, is used instead of os.LookupEnv to look up the
+	// values of variables.
+	Lookup   func(name string) (string, bool)
 	settings []Setting
 }
 
@@ -42,16 +77,18 @@ func (*EnvLoader) Name() string {
 /*
 Init initializes the loader with the given settings.
 
-Init must be called before Load.
+Init must be called before Load. It panics if two settings have the same
+variable name, such as two settings with the same `env` struct tag.
 */
 func (el *EnvLoader) Init(settings []Setting) {
+	settings = stringSettings(settings)
 	names := make(map[string]struct{}, len(settings))
-	for i := range el.settings {
-		name := el.transformName(el.settings[i].Path)
+	for i := range settings {
+		name := el.transformName(&settings[i])
 		if _, ok := names[name]; ok {
 			panic(fmt.Sprintf(
 				"duplicate environment variable name %s for %s",
-				name, el.settings[i].Path,
+				name, settings[i].Path,
 			))
 		}
 		names[name] = struct{}{}
@@ -63,12 +100,60 @@ func (el *EnvLoader) Init(settings []Setting) {
 Load attempts to parse the configured settings from environment variables.
 
 The returned error, if non-nil, will be of type Errors. Each element will be
-of type *ConversionError or *ValidationError.
+of type *ConversionError or *ValidationError, or an error reading a file named
+by a _FILE variable.
 */
 func (el *EnvLoader) Load() error {
+	lookup := el.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return el.load(func(name string) string {
+		val, _ := lookup(name)
+		return val
+	})
+}
+
+/*
+EnvironLookup returns a function for use as EnvLoader.Lookup, which looks up
+variables in environ.
+
+Each element of environ has the form "key=value", as returned by os.Environ. If
+a key is repeated, the last value is used.
+*/
+func EnvironLookup(environ []string) func(name string) (string, bool) {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if i := strings.Index(kv, "="); i >= 0 {
+			vars[kv[:i]] = kv[i+1:]
+		}
+	}
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+		return val, ok
+	}
+}
+
+/*
+load sets the configured settings using getenv to look up values.
+
+Empty values are ignored.
+*/
+func (el *EnvLoader) load(getenv func(string) string) error {
 	var errs Errors
 	for i := range el.settings {
-		if val := os.Getenv(el.transformName(el.settings[i].Path)); val != "" {
+		name := el.transformName(&el.settings[i])
+		val := getenv(name)
+		if filename := getenv(name + "_FILE"); val == "" && filename != "" &&
+			el.fileVariable(&el.settings[i]) {
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				errs.Append(fmt.Errorf("%s_FILE: %s", name, err))
+				continue
+			}
+			val = strings.TrimRight(string(data), "\r\n")
+		}
+		if val != "" {
 			if err := el.settings[i].Setter.Set(val); err != nil {
 				switch err := err.(type) {
 				case *ConversionError:
@@ -88,13 +173,25 @@ Usage returns a string with a list of environment variables names and their
 descriptions.
 */
 func (el *EnvLoader) Usage() string {
+	return el.usage("Environment Variables")
+}
+
+// usage returns a list of variable names and descriptions, headed by title.
+func (el *EnvLoader) usage(title string) string {
 	var b strings.Builder
-	b.WriteString("Environment Variables:\n")
+	b.WriteString(title)
+	b.WriteString(":\n")
 	for i := range el.settings {
+		name := el.transformName(&el.settings[i])
 		b.WriteString("  ")
-		b.WriteString(el.transformName(el.settings[i].Path))
+		b.WriteString(name)
 		b.WriteString("=")
 		b.WriteString(FriendlyTypeName(el.settings[i].Setter.Get()))
+		if el.fileVariable(&el.settings[i]) {
+			b.WriteString(", ")
+			b.WriteString(name)
+			b.WriteString("_FILE=path")
+		}
 		usage := strings.Replace(
 			el.settings[i].Tag.Get("help"), "\n", "\n    \t", -1,
 		)
@@ -113,14 +210,28 @@ func (el *EnvLoader) Usage() string {
 	return b.String()
 }
 
-func (el *EnvLoader) transformName(path *Path) string {
-	elements := path.Elements()
-	for i := range elements {
-		parts := SplitName(elements[i])
-		for i := range parts {
-			parts[i] = strings.Trim(strings.ToUpper(parts[i]), "-_")
-		}
-		elements[i] = strings.Join(parts, "_")
+// fileVariable returns true if a _FILE variable can be used for setting.
+func (el *EnvLoader) fileVariable(setting *Setting) bool {
+	if tag, ok := setting.Tag.Lookup("envfile"); ok {
+		b, _ := strconv.ParseBool(tag)
+		return b
+	}
+	return el.FileVariables
+}
+
+/*
+transformName returns the variable name for setting.
+
+This is the name given by its `env` struct tag, if any, or otherwise the name
+generated by el.Names, prefixed with el.Prefix.
+*/
+func (el *EnvLoader) transformName(setting *Setting) string {
+	if name := setting.Tag.Get("env"); name != "" {
+		return name
+	}
+	names := el.Names
+	if names == nil {
+		names = EnvNames
 	}
-	return strings.Join(elements, "_")
+	return el.Prefix + names.TransformName(setting.Path)
 }
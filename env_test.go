@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -141,4 +142,97 @@ func TestEnvLoader(t *testing.T) {
 			t.Errorf("unexpected value %s for duration", duration)
 		}
 	})
+
+	t.Run("lookup", func(t *testing.T) {
+		loader := &EnvLoader{Lookup: EnvironLookup([]string{
+			"TEST_ITERATIONS=5", "INVALID", "TEST_ITERATIONS=6",
+		})}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing env: %s", err)
+		}
+		if iterations == nil || *iterations != 6 {
+			t.Errorf("unexpected value %s for iteration", ptrStr(iterations))
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		loader := &EnvLoader{Prefix: "MYAPP_"}
+		loader.Init(settings)
+		var env env
+		defer env.Restore()
+		env.Set("TEST_ITERATIONS", "7")
+		env.Set("MYAPP_TEST_ITERATIONS", "8")
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing env: %s", err)
+		}
+		if iterations == nil || *iterations != 8 {
+			t.Errorf("unexpected value %s for iteration", ptrStr(iterations))
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "\n  MYAPP_TEST_ITERATIONS=int\n") {
+			t.Errorf("usage %q does not contain prefixed name", usage)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "name", "fromfile\n")
+		defer cleanup()
+		var env env
+		defer env.Restore()
+		env.Set("TEST_NAME", "")
+		env.Set("TEST_NAME_FILE", filename)
+
+		loader := new(EnvLoader)
+		loader.Init(settings)
+		*name = "mytest"
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing env: %s", err)
+		}
+		if *name != "mytest" {
+			t.Errorf("_FILE variable was used without being enabled")
+		}
+
+		loader.FileVariables = true
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing env: %s", err)
+		}
+		if *name != "fromfile" {
+			t.Errorf("unexpected value %s for name", ptrStr(name))
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "TEST_NAME=string, TEST_NAME_FILE=path\n") {
+			t.Errorf("usage %q does not mention TEST_NAME_FILE", usage)
+		}
+
+		env.Set("TEST_NAME", "fromenv")
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing env: %s", err)
+		}
+		if *name != "fromenv" {
+			t.Errorf("unexpected value %s for name", ptrStr(name))
+		}
+
+		env.Set("TEST_ITERATIONS_FILE", filename+".missing")
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 || !strings.HasPrefix((*errs)[0].Error(), "TEST_ITERATIONS_FILE: ") {
+			t.Errorf("unexpected error %v", err)
+		}
+
+		loader.settings[1].Tag = `envfile:"false"`
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing env: %s", err)
+		}
+	})
+	t.Run("duplicate name", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Init did not panic for duplicate variable names")
+			}
+		}()
+		var a, b string
+		new(EnvLoader).Init([]Setting{
+			newTestSetting(root, "a", `env:"X"`, &a),
+			newTestSetting(root, "b", `env:"X"`, &b),
+		})
+	})
 }
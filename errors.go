@@ -102,5 +102,28 @@ func (ve *ValidationError) Error() string {
 	return fmt.Sprintf("Validating %v failed at %s: %s", ve.Value, ve.Path, ve.Message)
 }
 
+/*
+FileError is returned when a configuration file can not be read or parsed.
+
+Line and Column are 1-based, and are 0 if the location within the file is not
+known.
+*/
+type FileError struct {
+	Filename string
+	Line     int
+	Column   int
+	Err      error
+}
+
+func (fe *FileError) Error() string {
+	switch {
+	case fe.Line == 0:
+		return fmt.Sprintf("%s: %s", fe.Filename, fe.Err)
+	case fe.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", fe.Filename, fe.Line, fe.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", fe.Filename, fe.Line, fe.Column, fe.Err)
+}
+
 // ErrHelp is returned when help is requested via the -h command line flag.
 var ErrHelp = errors.New("Help requested")
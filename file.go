@@ -0,0 +1,362 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+Decoder is an interface for parsing configuration files of a specific format.
+
+A Decoder parses a file into a tree of values, which FileLoader then matches to
+settings. The tree is made up of the following types:
+ * map[string]interface{} for tables, objects or sections. Keys correspond to
+   the prefixes of nested structs, or to the names of settings. Keys may
+   contain dots to indicate nesting.
+ * []interface{} or []map[string]interface{} for arrays. Each element is set in
+   turn, which appends to slice values. Tables within arrays are used to set
+   slices of structs.
+ * string, passed to Setter.Set.
+ * bool, passed to Setter.SetBool.
+ * Any integer type, passed to Setter.SetInt or Setter.SetUint.
+ * float32 or float64, passed to Setter.SetFloat.
+ * json.Number, passed to Setter.SetInt, Setter.SetUint or Setter.SetFloat,
+   depending on its value.
+ * time.Time, formatted using RFC 3339 and passed to Setter.Set.
+ * nil, which is ignored.
+ * *Located, wrapping any of the above with its position in the file.
+*/
+type Decoder interface {
+	// Extensions returns a list of file name extensions, including the
+	// leading dot, for files that the Decoder can parse. These are used to
+	// register the Decoder in a DecoderRegistry.
+	Extensions() []string
+	// Decode parses data into a tree. If the data can not be parsed, the
+	// implementation should return an error, and may return a *FileError to
+	// indicate the position of the error.
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+/*
+Located wraps a value within a tree returned by Decoder.Decode with its
+position in the file.
+
+If setting the value fails, FileLoader will return a *FileError with the
+position, making it easier to find the cause of the error. Line and Column are
+1-based, and Column may be 0 if it is not known.
+*/
+type Located struct {
+	Value  interface{}
+	Line   int
+	Column int
+}
+
+/*
+DecoderRegistry is a map of file name extensions to Decoders.
+
+Extensions include the leading dot, and are lowercase. It provides methods that
+can be used to find a Decoder for a given file.
+*/
+type DecoderRegistry map[string]Decoder
+
+/*
+Add adds a Decoder to the registry for each of the extensions returned by
+d.Extensions().
+
+If there is already a Decoder registered for an extension, it will be
+replaced.
+*/
+func (dr *DecoderRegistry) Add(d Decoder) {
+	if *dr == nil {
+		*dr = make(map[string]Decoder)
+	}
+	for _, ext := range d.Extensions() {
+		(*dr)[strings.ToLower(ext)] = d
+	}
+}
+
+/*
+GetDecoder returns the registered Decoder for the extension of filename, or nil
+if none is registered.
+
+Extensions are matched without regard to case.
+*/
+func (dr *DecoderRegistry) GetDecoder(filename string) Decoder {
+	return (*dr)[strings.ToLower(filepath.Ext(filename))]
+}
+
+/*
+Copy creates a copy of the registry.
+
+It is useful for modifying the existing registry without affecting other
+references to it.
+*/
+func (dr *DecoderRegistry) Copy() *DecoderRegistry {
+	n := make(DecoderRegistry, len(*dr))
+	for k, v := range *dr {
+		n[k] = v
+	}
+	return &n
+}
+
+/*
+DefaultDecoderRegistry provides a default registry.
+
+It supports Decoders for JSON, YAML, TOML, INI and Java properties files, and is
+used by FileLoader if no other registry is specified.
+*/
+var DefaultDecoderRegistry = DecoderRegistry{}
+
+/*
+FileLoader implements a Loader type to parse settings from configuration files.
+
+The format of each file is determined by its extension, which is used to find
+a Decoder in a DecoderRegistry. For example, with the DefaultDecoderRegistry,
+	loader := &config.FileLoader{
+		Filenames: []string{"/etc/app/defaults.toml", "app.yaml"},
+	}
+will parse both TOML and YAML files. Files are loaded in order, so values in
+later files override those in earlier ones.
+
+Keys in the tree returned by the Decoder are matched to the path of each
+setting. Nested tables correspond to the prefixes of nested structs, and keys
+to the names of individual settings:
+	opts := struct {
+		HTMLParser struct {
+			ElementIDs []string
+			Name       string
+		}
+	}{}
+can be set from the JSON file
+	{"html_parser": {"element_ids": ["head", "body"], "name": "parser"}}
+Keys are matched without regard to case, dashes or underscores, so
+"html-parser", "htmlParser" and "HTMLParser" are equivalent to "html_parser".
+Keys containing dots are treated as nested names, so {"html_parser.name": "x"}
+is also accepted. Keys that do not match any setting are ignored.
+
+If Filenames is empty, files can instead be found by searching for each of
+SearchNames in each of SearchDirs, in order. For example,
+	loader := &config.FileLoader{
+		SearchDirs:  config.DefaultSearchDirs("app"),
+		SearchNames: []string{"config.yaml", "config.toml"},
+	}
+loads the first of those files found in the standard locations given by
+DefaultSearchDirs. If SearchAll is true, every file found is loaded instead, so
+that values in files found later in the search override those found earlier.
+Usage reports the files that were searched for and those that were loaded.
+
+The zero value is ready to use, but Load does nothing until Filenames or
+SearchNames is set.
+*/
+type FileLoader struct {
+	// Filenames lists the files to load.
+	Filenames []string
+	// SearchDirs lists directories to search for SearchNames if Filenames is
+	// empty.
+	SearchDirs []string
+	// SearchNames lists the names of files to search for in each of
+	// SearchDirs.
+	SearchNames []string
+	// SearchAll causes all files found by searching to be loaded, rather
+	// than only the first.
+	SearchAll bool
+	// Decoders is used to find a Decoder for each file. If nil, the
+	// DefaultDecoderRegistry is used.
+	Decoders DecoderRegistry
+	settings []Setting
+	searched []string
+	files    []string
+	loaded   map[string]bool
+}
+
+// Name returns the name of the loader. It is always "file".
+func (*FileLoader) Name() string {
+	return "file"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. It panics if two settings would be matched by
+the same key.
+*/
+func (fl *FileLoader) Init(settings []Setting) {
+	newKeyIndex(settings)
+	fl.settings = settings
+}
+
+/*
+Load attempts to parse the configured settings from each of fl.Filenames, or
+from the files found by searching if fl.Filenames is empty.
+
+The returned error, if non-nil, will be of type Errors. Each element will be an
+error returned from reading a file; a *FileError if the file could not be
+parsed; a *ConversionError or *ValidationError; or a *FileError wrapping a
+*ConversionError or *ValidationError if the Decoder provided the position of
+the value.
+*/
+func (fl *FileLoader) Load() error {
+	index := newKeyIndex(fl.settings)
+	fl.searched, fl.files = nil, fl.Filenames
+	if len(fl.files) == 0 {
+		fl.searched, fl.files = fl.search()
+	}
+	fl.loaded = make(map[string]bool, len(fl.files))
+	var errs Errors
+	for _, filename := range fl.files {
+		if err := fl.loadFile(index, filename); err != nil {
+			errs.Append(err)
+		}
+	}
+	return errs.AsError()
+}
+
+/*
+search looks for each of fl.SearchNames in each of fl.SearchDirs.
+
+It returns the paths that were searched, and those that were found. Unless
+fl.SearchAll is true, searching stops at the first file found.
+*/
+func (fl *FileLoader) search() (searched, found []string) {
+	for _, dir := range fl.SearchDirs {
+		for _, name := range fl.SearchNames {
+			path := filepath.Join(dir, name)
+			searched = append(searched, path)
+			if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+				continue
+			}
+			found = append(found, path)
+			if !fl.SearchAll {
+				return searched, found
+			}
+		}
+	}
+	return searched, found
+}
+
+func (fl *FileLoader) loadFile(index keyIndex, filename string) error {
+	reg := fl.Decoders
+	if reg == nil {
+		reg = DefaultDecoderRegistry
+	}
+	decoder := reg.GetDecoder(filename)
+	if decoder == nil {
+		return &FileError{
+			Filename: filename,
+			Err: fmt.Errorf(
+				"no decoder for file extension %q", filepath.Ext(filename),
+			),
+		}
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	tree, err := decodeFile(decoder, filename, data)
+	if err != nil {
+		return err
+	}
+	fl.loaded[filename] = true
+	return applyFile(index, filename, tree)
+}
+
+/*
+decodeFile decodes data using decoder.
+
+An error, if returned, will be of type *FileError, with its Filename set to
+filename.
+*/
+func decodeFile(decoder Decoder, filename string, data []byte) (map[string]interface{}, error) {
+	tree, err := decoder.Decode(data)
+	if err != nil {
+		if fe, ok := err.(*FileError); ok {
+			fe.Filename = filename
+			return nil, fe
+		}
+		return nil, &FileError{Filename: filename, Err: err}
+	}
+	return tree, nil
+}
+
+// applyFile sets values from tree, setting filename in any returned *FileError.
+func applyFile(index keyIndex, filename string, tree map[string]interface{}) error {
+	err := index.apply(tree)
+	if errs, ok := err.(*Errors); ok {
+		for i := range *errs {
+			if fe, ok := (*errs)[i].(*FileError); ok {
+				fe.Filename = filename
+			}
+		}
+	}
+	return err
+}
+
+/*
+Usage returns a string with a list of file keys and their descriptions.
+
+If Load has searched for files, the list is preceded by the paths that were
+searched, noting those that were loaded.
+*/
+func (fl *FileLoader) Usage() string {
+	var b strings.Builder
+	if len(fl.searched) != 0 {
+		b.WriteString("Configuration File Search Paths:\n")
+		for _, path := range fl.searched {
+			b.WriteString("  ")
+			b.WriteString(path)
+			if fl.loaded[path] {
+				b.WriteString(" (loaded)")
+			}
+			b.WriteString("\n")
+		}
+	}
+	files := fl.Filenames
+	if fl.loaded != nil {
+		files = files[:0:0]
+		for _, filename := range fl.files {
+			if fl.loaded[filename] {
+				files = append(files, filename)
+			}
+		}
+	}
+	b.WriteString(keyUsage("Configuration Files "+strings.Join(files, ", "), fl.settings, nil))
+	return b.String()
+}
+
+/*
+DefaultSearchDirs returns a list of standard directories to search for the
+configuration files of the named application, for use with
+FileLoader.SearchDirs.
+
+The directories are, in order:
+ * $XDG_CONFIG_HOME/app, or $HOME/.config/app if XDG_CONFIG_HOME is not set.
+ * app in each directory in $XDG_CONFIG_DIRS, or /etc/xdg/app if
+   XDG_CONFIG_DIRS is not set.
+ * /etc/app.
+ * The current working directory.
+Relative paths in the XDG environment variables are ignored, as required by
+the XDG Base Directory Specification.
+*/
+func DefaultSearchDirs(app string) []string {
+	var dirs []string
+	home := os.Getenv("XDG_CONFIG_HOME")
+	if home == "" && os.Getenv("HOME") != "" {
+		home = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	if filepath.IsAbs(home) {
+		dirs = append(dirs, filepath.Join(home, app))
+	}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range filepath.SplitList(configDirs) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, filepath.Join(dir, app))
+		}
+	}
+	return append(dirs, filepath.Join("/etc", app), ".")
+}
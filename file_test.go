@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTempFile writes content to a file with the given name in a new
+// temporary directory. The returned function removes the directory.
+func writeTempFile(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %s", err)
+	}
+	filename := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed writing temp file: %s", err)
+	}
+	return filename, func() { os.RemoveAll(dir) }
+}
+
+// testDecoder decodes the contents of a file as a single key with value "x".
+type testDecoder struct{}
+
+func (testDecoder) Extensions() []string {
+	return []string{".Test"}
+}
+
+func (testDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty file")
+	}
+	return map[string]interface{}{string(data): &Located{Value: "x", Line: 1}}, nil
+}
+
+func TestDecoderRegistry(t *testing.T) {
+	var reg DecoderRegistry
+	reg.Add(testDecoder{})
+	if _, ok := reg.GetDecoder("/a/b.TEST").(testDecoder); !ok {
+		t.Error("decoder not found for .TEST extension")
+	}
+	if d := reg.GetDecoder("b.json"); d != nil {
+		t.Errorf("unexpected decoder %T for .json extension", d)
+	}
+
+	c := DefaultDecoderRegistry.Copy()
+	c.Add(testDecoder{})
+	if DefaultDecoderRegistry.GetDecoder("b.test") != nil {
+		t.Error("adding to a copy modified the original registry")
+	}
+	for _, name := range []string{"a.json", "a.yaml", "a.yml", "a.toml", "a.ini", "a.properties", "a.test"} {
+		if c.GetDecoder(name) == nil {
+			t.Errorf("no decoder for %s", name)
+		}
+	}
+}
+
+func TestFileLoader(t *testing.T) {
+	root := NewRootPath("")
+	var name string
+	var port int
+	settings := settings{
+		{
+			Path: root.AddPath(root.NewPath("Name")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&name).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Port")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&port).Elem(), `max:"65535"`,
+			),
+		},
+	}
+
+	t.Run("multiple files", func(t *testing.T) {
+		first, cleanup := writeTempFile(t, "first.json", `{"name": "first", "port": 80}`)
+		defer cleanup()
+		second, cleanup := writeTempFile(t, "second.yaml", "name: second\n")
+		defer cleanup()
+		loader := &FileLoader{Filenames: []string{first, second}}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if name != "second" || port != 80 {
+			t.Errorf("unexpected values %q, %d", name, port)
+		}
+	})
+
+	t.Run("custom decoder", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.test", "port")
+		defer cleanup()
+		reg := DefaultDecoderRegistry.Copy()
+		reg.Add(testDecoder{})
+		loader := &FileLoader{Filenames: []string{filename}, Decoders: *reg}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		fe, ok := (*errs)[0].(*FileError)
+		if !ok || fe.Filename != filename || fe.Line != 1 {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+		if _, ok := fe.Err.(*ConversionError); !ok {
+			t.Errorf("unexpected error %s", fe.Err)
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.test", "")
+		defer cleanup()
+		reg := DecoderRegistry{}
+		reg.Add(testDecoder{})
+		loader := &FileLoader{Filenames: []string{filename}, Decoders: reg}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if fe, ok := (*errs)[0].(*FileError); !ok || fe.Filename != filename {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+
+	t.Run("unknown extension", func(t *testing.T) {
+		loader := &FileLoader{Filenames: []string{"config.test"}}
+		loader.Init(settings)
+		err := loader.Load()
+		if errs, ok := err.(*Errors); !ok || len(*errs) != 1 {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		loader := &FileLoader{Filenames: []string{"/nonexistent/config.json"}}
+		loader.Init(settings)
+		if err := loader.Load(); err == nil {
+			t.Error("loading a missing file did not return an error")
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		first, cleanup := writeTempFile(t, "config.json", `{"name": "first", "port": 80}`)
+		defer cleanup()
+		second, cleanup := writeTempFile(t, "config.yaml", "name: second\n")
+		defer cleanup()
+		missing := filepath.Join(filepath.Dir(first), "config.yaml")
+		loader := &FileLoader{
+			SearchDirs:  []string{filepath.Dir(first), filepath.Dir(second)},
+			SearchNames: []string{"config.yaml", "config.json"},
+		}
+		loader.Init(settings)
+		name, port = "", 0
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if name != "first" || port != 80 {
+			t.Errorf("unexpected values %q, %d", name, port)
+		}
+		usage := loader.Usage()
+		for _, s := range []string{missing + "\n", first + " (loaded)\n", "Configuration Files " + first + ":\n"} {
+			if !strings.Contains(usage, s) {
+				t.Errorf("usage %q does not contain %q", usage, s)
+			}
+		}
+		if strings.Contains(usage, second) {
+			t.Errorf("usage %q contains %s", usage, second)
+		}
+
+		loader.SearchAll = true
+		name, port = "", 0
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if name != "second" || port != 80 {
+			t.Errorf("unexpected values %q, %d", name, port)
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, second+" (loaded)\n") {
+			t.Errorf("usage %q does not contain %s", usage, second)
+		}
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		loader := new(FileLoader)
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error %s", err)
+		}
+	})
+}
+
+func TestDefaultSearchDirs(t *testing.T) {
+	var e env
+	defer e.Restore()
+	e.Set("HOME", "/home/user")
+	e.Set("XDG_CONFIG_HOME", "")
+	e.Set("XDG_CONFIG_DIRS", "/etc/xdg1:relative:/etc/xdg2")
+	expected := []string{
+		"/home/user/.config/app",
+		"/etc/xdg1/app",
+		"/etc/xdg2/app",
+		"/etc/app",
+		".",
+	}
+	if dirs := DefaultSearchDirs("app"); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("unexpected dirs %q", dirs)
+	}
+	e.Set("XDG_CONFIG_HOME", "/config")
+	e.Set("XDG_CONFIG_DIRS", "")
+	expected = []string{"/config/app", "/etc/xdg/app", "/etc/app", "."}
+	if dirs := DefaultSearchDirs("app"); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("unexpected dirs %q", dirs)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 /*
@@ -30,10 +33,50 @@ with dashes to produce the final names:
 	-html-parser-element-ids
 	-html-parser-name
 
+A different naming scheme can be used by setting Names. The name of an
+individual setting can instead be given with the `flag` struct tag:
+	DatabaseURL string `flag:"db"`
+
+Flags are parsed from the command line arguments of the process unless
+Arguments is set.
+
+By default, flags are parsed by the flag package, and are given with a single
+dash. If GNU is set, flags are instead parsed in the style of GNU getopt_long:
+long names are given with two dashes, as --name=value or --name value, and a
+setting may also have a single letter alias given with the `short` struct tag:
+	Verbose bool `short:"v"`
+Short boolean flags can be combined, so that -abc is the same as -a -b -c, and
+the value of any other short flag can follow it directly, as in -ofile. As with
+the flag package, parsing stops at the first argument that is not a flag, or
+after the terminator "--". If GNU is not set, `short` tags are ignored, so the
+same struct can be used with either style of parsing.
+
+Boolean settings can also be turned off with a flag prefixed with "no-", such
+as -no-verbose, or --no-verbose if GNU is set, unless a setting already has
+that name.
+
+An integer setting with the `count:"true"` struct tag is set without a value,
+and is incremented each time its flag is given, so that -v -v -v, or -vvv with
+a short flag, sets it to 3. Any `min`, `max`, `lt` or `gt` validation applies to
+the count. A value can still be given explicitly, as in -v=2.
+
 The zero value is ready to use.
 */
 type FlagLoader struct {
-	fs *flag.FlagSet
+	// Names generates the name of the flag for each setting. If nil,
+	// FlagNames is used.
+	Names NameTransformer
+	// Arguments, if not nil, is parsed by Load instead of os.Args[1:].
+	Arguments []string
+	// ProgramName, if not empty, is used instead of os.Args[0] as the name
+	// of the program in error messages.
+	ProgramName string
+	// GNU enables parsing of GNU-style long and short flags.
+	GNU   bool
+	fs    *flag.FlagSet
+	short map[string]string
+	args  []string
+	title string
 }
 
 // Name returns the name of the loader. It is always "flag".
@@ -44,27 +87,124 @@ func (*FlagLoader) Name() string {
 /*
 Init initializes the loader with the given settings.
 
-Init must be called before Load.
+Init must be called before Load. It panics if two settings have the same flag
+name, if a `short` tag is not a single character or is used twice, or if a
+`count` tag is used with a setting which is not an integer.
 */
 func (fl *FlagLoader) Init(settings []Setting) {
-	fl.fs = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	program := fl.ProgramName
+	if program == "" && len(os.Args) != 0 {
+		program = os.Args[0]
+	}
+	fl.fs = flag.NewFlagSet(program, flag.ContinueOnError)
 	fl.fs.SetOutput(ioutil.Discard)
+	fl.short = make(map[string]string)
+	fl.args = nil
+	settings = stringSettings(settings)
 	for i := range settings {
 		path := settings[i].Path
-		name := fl.transformName(settings[i].Path)
+		name := fl.transformName(&settings[i])
 		if fl.fs.Lookup(name) != nil {
 			panic(fmt.Sprintf("duplicate flag name %s for %s", name, path))
 		}
+		if short := settings[i].Tag.Get("short"); short != "" {
+			if utf8.RuneCountInString(short) != 1 {
+				panic(fmt.Sprintf("invalid short flag %q for %s", short, path))
+			}
+			if _, ok := fl.short[short]; ok {
+				panic(fmt.Sprintf("duplicate short flag %s for %s", short, path))
+			}
+			fl.short[short] = name
+		}
 		help := settings[i].Tag.Get("help")
-		fl.fs.Var(settings[i].Setter, name, help)
+		var value flag.Value = settings[i].Setter
+		if count, _ := strconv.ParseBool(settings[i].Tag.Get("count")); count {
+			if !isIntSetter(settings[i].Setter) {
+				panic(fmt.Sprintf("count tag for non-integer setting %s", path))
+			}
+			value = &countFlag{settings[i].Setter}
+		}
+		fl.fs.Var(value, name, help)
+	}
+	for i := range settings {
+		if !isBoolFlag(settings[i].Setter) {
+			continue
+		}
+		name := "no-" + fl.transformName(&settings[i])
+		if fl.fs.Lookup(name) == nil {
+			fl.fs.Var(negatedFlag{settings[i].Setter}, name, "")
+		}
+	}
+}
+
+// negatedFlag is a flag.Value which sets the negation of its value on a
+// boolean Setter.
+type negatedFlag struct {
+	Setter
+}
+
+func (nf negatedFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (nf negatedFlag) String() string {
+	return ""
+}
+
+func (nf negatedFlag) Set(val string) error {
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return &ConversionError{Value: val, ToType: boolType}
 	}
+	return nf.Setter.Set(strconv.FormatBool(!b))
+}
+
+// countFlag is a flag.Value which increments an integer Setter when set to
+// true.
+type countFlag struct {
+	Setter
+}
+
+func (cf *countFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (cf *countFlag) Set(val string) error {
+	if val != "true" {
+		return cf.Setter.Set(val)
+	}
+	v := reflect.ValueOf(cf.Setter.Get())
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return cf.Setter.SetInt(1)
+		}
+		v = v.Elem()
+	}
+	return cf.Setter.SetInt(v.Int() + 1)
+}
+
+// isIntSetter returns whether setter sets a signed integer, or a pointer to one.
+func isIntSetter(setter Setter) bool {
+	t := reflect.TypeOf(setter.Get())
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
 }
 
 /*
 SetUsageFn sets a function to be executed to provide usage information.
 
-If the -h flag is not overridden and is encountered on the command line when
-fl.Load is called, the function provided will be called.
+If the -h flag (or --help, if fl.GNU is set) is not overridden and is
+encountered on the command line when fl.Load is called, the function provided
+will be called.
 
 This method is called by (*Config).Load to provide detailed usage information
 for all loaders.
@@ -79,12 +219,16 @@ func (fl *FlagLoader) SetUsageFn(fn func()) {
 Parse loads arguments from the provided slice.
 
 It is similar to fl.Load except that arguments are provided instead of being
-loaded from os.Args[1:]. It is useful primarily for testing.
+loaded from fl.Arguments or os.Args[1:].
 
 The returned error, if non-nil, will be either ErrHelp or an error value
-returned by (*flag.FlagSet).Parse.
+returned by (*flag.FlagSet).Parse or, if fl.GNU is set, describing the invalid
+flag.
 */
 func (fl *FlagLoader) Parse(args []string) error {
+	if fl.GNU {
+		return fl.parseGNU(args)
+	}
 	err := fl.fs.Parse(args)
 	if err == flag.ErrHelp {
 		return ErrHelp
@@ -92,35 +236,161 @@ func (fl *FlagLoader) Parse(args []string) error {
 	return err
 }
 
+// parseGNU parses args as GNU-style flags.
+func (fl *FlagLoader) parseGNU(args []string) error {
+	fl.args = []string{}
+	for len(args) != 0 {
+		arg := args[0]
+		if arg == "--" {
+			args = args[1:]
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		var err error
+		if arg[1] == '-' {
+			args, err = fl.parseLong(arg[2:], args[1:])
+		} else {
+			args, err = fl.parseShort(arg[1:], args[1:])
+		}
+		if err != nil {
+			if fl.fs.Usage != nil {
+				fl.fs.Usage()
+			}
+			return err
+		}
+	}
+	fl.args = args
+	return nil
+}
+
+// parseLong parses the long flag name, which may include a value, taking its
+// value from args if needed. It returns the remaining arguments.
+func (fl *FlagLoader) parseLong(name string, args []string) ([]string, error) {
+	value, hasValue := "", false
+	if i := strings.Index(name, "="); i >= 0 {
+		name, value, hasValue = name[:i], name[i+1:], true
+	}
+	f := fl.fs.Lookup(name)
+	if f == nil {
+		if name == "help" {
+			return args, ErrHelp
+		}
+		return args, fmt.Errorf("flag provided but not defined: --%s", name)
+	}
+	if !hasValue {
+		if isBoolFlag(f.Value) {
+			value = "true"
+		} else if len(args) != 0 {
+			value, args = args[0], args[1:]
+		} else {
+			return args, fmt.Errorf("flag needs an argument: --%s", name)
+		}
+	}
+	return args, fl.set(f, "--"+name, value)
+}
+
+// parseShort parses a group of short flags, taking the value of the last flag
+// from args if needed. It returns the remaining arguments.
+func (fl *FlagLoader) parseShort(group string, args []string) ([]string, error) {
+	for group != "" {
+		_, size := utf8.DecodeRuneInString(group)
+		short := group[:size]
+		group = group[size:]
+		name, ok := fl.short[short]
+		if !ok {
+			if short == "h" {
+				return args, ErrHelp
+			}
+			return args, fmt.Errorf("flag provided but not defined: -%s", short)
+		}
+		f := fl.fs.Lookup(name)
+		if isBoolFlag(f.Value) {
+			if err := fl.set(f, "-"+short, "true"); err != nil {
+				return args, err
+			}
+			continue
+		}
+		value := group
+		if value == "" {
+			if len(args) == 0 {
+				return args, fmt.Errorf("flag needs an argument: -%s", short)
+			}
+			value, args = args[0], args[1:]
+		}
+		return args, fl.set(f, "-"+short, value)
+	}
+	return args, nil
+}
+
+// set sets the value of f, given on the command line as arg.
+func (fl *FlagLoader) set(f *flag.Flag, arg, value string) error {
+	if err := fl.fs.Set(f.Name, value); err != nil {
+		return fmt.Errorf("invalid value %q for flag %s: %v", value, arg, err)
+	}
+	return nil
+}
+
 /*
 Args returns any command-line arguments that are left after parsing arguments.
 
 It returns nil if Load() has not been called.
 */
 func (fl *FlagLoader) Args() []string {
+	if fl.GNU {
+		return fl.args
+	}
 	return fl.fs.Args()
 }
 
 /*
-Load attempts to parse the configured settings from command line flags.
+Load attempts to parse the configured settings from command line flags, given
+by fl.Arguments or os.Args[1:].
 
 The returned error, if non-nil, will be either ErrHelp or an error value
 returned by (*flag.FlagSet).Parse.
 */
 func (fl *FlagLoader) Load() error {
+	if fl.Arguments != nil {
+		return fl.Parse(fl.Arguments)
+	}
 	return fl.Parse(os.Args[1:])
 }
 
 // Usage returns a string with a list of command line flags and their descriptions.
 func (fl *FlagLoader) Usage() string {
 	var b strings.Builder
-	b.WriteString("Command Line Flags:\n")
+	title := fl.title
+	if title == "" {
+		title = "Command Line Flags"
+	}
+	b.WriteString(title)
+	b.WriteString(":\n")
 	if fl.fs != nil {
+		shorts := make(map[string]string, len(fl.short))
+		for short, name := range fl.short {
+			shorts[name] = short
+		}
 		fl.fs.VisitAll(func(f *flag.Flag) {
+			if _, ok := f.Value.(negatedFlag); ok {
+				return
+			}
 			b.WriteString("  -")
+			if fl.GNU {
+				if short, ok := shorts[f.Name]; ok {
+					b.WriteString(short)
+					b.WriteString(", -")
+				}
+				b.WriteString("-")
+			}
+			if nf := fl.fs.Lookup("no-" + f.Name); nf != nil {
+				if _, ok := nf.Value.(negatedFlag); ok {
+					b.WriteString("[no-]")
+				}
+			}
 			b.WriteString(f.Name)
-			ibf, ok := f.Value.(interface{ IsBoolFlag() bool })
-			if !ok || !ibf.IsBoolFlag() {
+			if !isBoolFlag(f.Value) {
 				b.WriteString(" ")
 				if getter, ok := f.Value.(flag.Getter); ok {
 					b.WriteString(FriendlyTypeName(getter.Get()))
@@ -145,14 +415,25 @@ func (fl *FlagLoader) Usage() string {
 	return b.String()
 }
 
-func (fl *FlagLoader) transformName(path *Path) string {
-	elements := path.Elements()
-	for i := range elements {
-		parts := SplitName(elements[i])
-		for i := range parts {
-			parts[i] = strings.Trim(strings.ToLower(parts[i]), "-_")
-		}
-		elements[i] = strings.Join(parts, "-")
+/*
+transformName returns the flag name for setting.
+
+This is the name given by its `flag` struct tag, if any, or otherwise the name
+generated by fl.Names.
+*/
+func (fl *FlagLoader) transformName(setting *Setting) string {
+	if name := setting.Tag.Get("flag"); name != "" {
+		return name
+	}
+	names := fl.Names
+	if names == nil {
+		names = FlagNames
 	}
-	return strings.Join(elements, "-")
+	return names.TransformName(setting.Path)
+}
+
+// isBoolFlag returns whether v is a boolean flag, which needs no value.
+func isBoolFlag(v flag.Value) bool {
+	ibf, ok := v.(interface{ IsBoolFlag() bool })
+	return ok && ibf.IsBoolFlag()
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -116,4 +117,224 @@ func TestFlagLoader(t *testing.T) {
 			t.Errorf("unexpected value %s for duration", duration)
 		}
 	})
+
+	t.Run("negate", func(t *testing.T) {
+		loader := new(FlagLoader)
+		loader.Init(settings)
+		args := []string{"-test-verbose", "-no-test-verbose"}
+		if err := loader.Parse(args); err != nil {
+			t.Errorf("unexpected error parsing args %s: %s", args, err)
+		}
+		if verbose == nil || *verbose {
+			t.Errorf("unexpected value %s for verbose", ptrStr(verbose))
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "  -[no-]test-verbose\n") || strings.Contains(usage, "no-test-name") {
+			t.Errorf("unexpected usage %q", usage)
+		}
+	})
+
+	t.Run("arguments", func(t *testing.T) {
+		loader := &FlagLoader{
+			Arguments:   []string{"-test-iterations", "4", "extra"},
+			ProgramName: "prog",
+		}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error parsing args: %s", err)
+		}
+		if iterations == nil || *iterations != 4 {
+			t.Errorf("unexpected value %s for iteration", ptrStr(iterations))
+		}
+		if args := loader.Args(); len(args) != 1 || args[0] != "extra" {
+			t.Errorf("unexpected args %s", args)
+		}
+		if name := loader.fs.Name(); name != "prog" {
+			t.Errorf("unexpected program name %s", name)
+		}
+	})
+}
+
+func TestFlagLoaderGNU(t *testing.T) {
+	var (
+		all     bool
+		brief   bool
+		output  string
+		name    string
+		verbose bool
+	)
+	root := NewRootPath("")
+	settings := []Setting{
+		newTestSetting(root, "all", `short:"a"`, &all),
+		newTestSetting(root, "brief", `short:"b" help:"Be brief."`, &brief),
+		newTestSetting(root, "output", `short:"o"`, &output),
+		newTestSetting(root, "name", ``, &name),
+		newTestSetting(root, "verbose", `short:"v"`, &verbose),
+	}
+	reset := func() {
+		all, brief, output, name, verbose = false, false, "", "", false
+	}
+
+	tests := []struct {
+		args    []string
+		all     bool
+		brief   bool
+		output  string
+		name    string
+		verbose bool
+		rest    []string
+	}{
+		{args: []string{"--all", "--name=x", "--output", "out"}, all: true, name: "x", output: "out"},
+		{args: []string{"-ab", "-v", "-oout"}, all: true, brief: true, verbose: true, output: "out"},
+		{args: []string{"-vo", "out", "extra", "-a"}, verbose: true, output: "out", rest: []string{"extra", "-a"}},
+		{args: []string{"--brief=false", "--", "--all"}, rest: []string{"--all"}},
+		{args: []string{"--name", "-", "-"}, name: "-", rest: []string{"-"}},
+		{args: []string{"-ab", "--no-all"}, brief: true},
+		{args: []string{"-v", "--no-verbose=false"}, verbose: true},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			reset()
+			loader := &FlagLoader{GNU: true}
+			loader.Init(settings)
+			if err := loader.Parse(test.args); err != nil {
+				t.Fatalf("unexpected error parsing args %s: %s", test.args, err)
+			}
+			if all != test.all || brief != test.brief || verbose != test.verbose {
+				t.Errorf(
+					"unexpected values %t, %t, %t for all, brief and verbose",
+					all, brief, verbose,
+				)
+			}
+			if output != test.output || name != test.name {
+				t.Errorf("unexpected values %q, %q for output and name", output, name)
+			}
+			if args := loader.Args(); !reflect.DeepEqual(args, append([]string{}, test.rest...)) {
+				t.Errorf("unexpected args %q", args)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--unknown"},
+			{"-x"},
+			{"-ax"},
+			{"--output"},
+			{"-o"},
+			{"--all=maybe"},
+			{"-all"},
+		} {
+			loader := &FlagLoader{GNU: true}
+			loader.Init(settings)
+			if err := loader.Parse(args); err == nil || err == ErrHelp {
+				t.Errorf("unexpected error %v parsing args %s", err, args)
+			}
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		for _, args := range [][]string{{"-h"}, {"--help"}, {"-ah"}} {
+			called := false
+			loader := &FlagLoader{GNU: true}
+			loader.Init(settings)
+			loader.SetUsageFn(func() { called = true })
+			if err := loader.Parse(args); err != ErrHelp {
+				t.Errorf("unexpected error %v parsing args %s", err, args)
+			}
+			if !called {
+				t.Errorf("usage function not called for args %s", args)
+			}
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		loader := &FlagLoader{GNU: true}
+		loader.Init(settings)
+		usage := loader.Usage()
+		for _, s := range []string{
+			"  -a, --[no-]all\n",
+			"  -b, --[no-]brief\n    \tBe brief.\n",
+			"  -o, --output string\n",
+			"  --name string\n",
+		} {
+			if !strings.Contains(usage, s) {
+				t.Errorf("usage %q does not contain %q", usage, s)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, tag := range []string{`short:"ab"`, `short:"a"`} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("Init did not panic for tag %s", tag)
+					}
+				}()
+				new(FlagLoader).Init([]Setting{
+					settings[0],
+					newTestSetting(root, "other", tag, new(bool)),
+				})
+			}()
+		}
+	})
+}
+
+func TestFlagLoaderCount(t *testing.T) {
+	var verbose int
+	var level *int8
+	root := NewRootPath("")
+	settings := []Setting{
+		newTestSetting(root, "verbose", `short:"v" count:"true" max:"3"`, &verbose),
+		newTestSetting(root, "level", `short:"l" count:"true"`, &level),
+	}
+
+	tests := []struct {
+		gnu     bool
+		args    []string
+		verbose int
+		level   int8
+		err     bool
+	}{
+		{args: []string{"-verbose", "-verbose", "-level"}, verbose: 2, level: 1},
+		{args: []string{"-verbose=2", "-verbose"}, verbose: 3},
+		{gnu: true, args: []string{"-vvv", "-ll"}, verbose: 3, level: 2},
+		{gnu: true, args: []string{"--verbose", "-lv"}, verbose: 2, level: 1},
+		{gnu: true, args: []string{"-vvvv"}, verbose: 3, err: true},
+		{args: []string{"-verbose=4"}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			verbose, level = 0, nil
+			loader := &FlagLoader{GNU: test.gnu}
+			loader.Init(settings)
+			err := loader.Parse(test.args)
+			if test.err != (err != nil) {
+				t.Errorf("unexpected error %v parsing args %s", err, test.args)
+			}
+			if verbose != test.verbose {
+				t.Errorf("unexpected value %d for verbose", verbose)
+			}
+			if test.level == 0 && level != nil || test.level != 0 && (level == nil || *level != test.level) {
+				t.Errorf("unexpected value %v for level", level)
+			}
+		})
+	}
+
+	t.Run("usage", func(t *testing.T) {
+		loader := &FlagLoader{GNU: true}
+		loader.Init(settings)
+		if usage := loader.Usage(); !strings.Contains(usage, "  -v, --verbose\n") {
+			t.Errorf("unexpected usage %q", usage)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Init did not panic for count tag on a string")
+			}
+		}()
+		new(FlagLoader).Init([]Setting{newTestSetting(root, "name", `count:"true"`, new(string))})
+	})
 }
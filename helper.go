@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+/*
+HelperLoader implements a Loader type to set values from the output of helper
+commands, such as password managers or the command line tools of cloud
+providers.
+
+Only settings with a `helper` struct tag are set. The tag gives the command to
+run, split into arguments on white space:
+	DBPassword string `helper:"pass show db/password" from:"helper"`
+The command is run without a shell. The path of the setting, in the form used
+by FileLoader, followed by a newline, is written to the command's standard
+input, so that a single helper can serve many settings. Its standard output,
+without trailing newlines, is passed to Setter.Set.
+
+If a command does not exit within Timeout, it is killed, and any processes it
+started are no longer waited for. A command that exits with a non-zero status
+results in an error including the status and the command's standard error.
+
+The zero value is ready to use.
+*/
+type HelperLoader struct {
+	// Timeout limits the time each command may run. If zero, a timeout of
+	// 10 seconds is used.
+	Timeout  time.Duration
+	settings []Setting
+}
+
+// Name returns the name of the loader. It is always "helper".
+func (*HelperLoader) Name() string {
+	return "helper"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. Settings without a `helper` tag are ignored.
+It panics if a `helper` tag is empty.
+*/
+func (hl *HelperLoader) Init(settings []Setting) {
+	hl.settings = nil
+	settings = stringSettings(settings)
+	for i := range settings {
+		tag, ok := settings[i].Tag.Lookup("helper")
+		if !ok {
+			continue
+		}
+		if len(strings.Fields(tag)) == 0 {
+			panic(fmt.Sprintf("empty helper tag for %s", settings[i].Path))
+		}
+		hl.settings = append(hl.settings, settings[i])
+	}
+}
+
+/*
+Load runs the helper command for each configured setting.
+
+The returned error, if non-nil, will be of type Errors. Each element will be an
+error from running a command, or of type *ConversionError or *ValidationError.
+*/
+func (hl *HelperLoader) Load() error {
+	var errs Errors
+	for i := range hl.settings {
+		val, err := hl.run(&hl.settings[i])
+		if err != nil {
+			errs.Append(err)
+			continue
+		}
+		if err := hl.settings[i].Setter.Set(val); err != nil {
+			setErrorPath(err, hl.settings[i].Path)
+			errs.Append(err)
+		}
+	}
+	return errs.AsError()
+}
+
+// run runs the helper command for setting, returning its output.
+func (hl *HelperLoader) run(setting *Setting) (string, error) {
+	command := setting.Tag.Get("helper")
+	args := strings.Fields(command)
+	timeout := hl.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(displayKey(setting.Path) + "\n")
+	var stdout, stderr bytes.Buffer
+	if err := runContext(ctx, cmd, &stdout, &stderr); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf(
+				"helper %q for %s: timed out after %s",
+				command, setting.Path, timeout,
+			)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf(
+				"helper %q for %s: %s: %s", command, setting.Path, err, msg,
+			)
+		}
+		return "", fmt.Errorf("helper %q for %s: %s", command, setting.Path, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+/*
+runContext runs cmd, which must have been created with ctx, writing its
+standard output and error to stdout and stderr.
+
+Unlike cmd.Run, it stops waiting for output once ctx is done. Killing cmd only
+kills the process it started, so any child processes, such as those of a shell
+script, could otherwise hold the output open until they exit.
+*/
+func runContext(ctx context.Context, cmd *exec.Cmd, stdout, stderr io.Writer) error {
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer outR.Close()
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outW.Close()
+		return err
+	}
+	defer errR.Close()
+	cmd.Stdout, cmd.Stderr = outW, errW
+	err = cmd.Start()
+	outW.Close()
+	errW.Close()
+	if err != nil {
+		return err
+	}
+	copied := make(chan struct{}, 2)
+	go func() {
+		io.Copy(stdout, outR)
+		copied <- struct{}{}
+	}()
+	go func() {
+		io.Copy(stderr, errR)
+		copied <- struct{}{}
+	}()
+	err = cmd.Wait()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-copied:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// Usage returns a string with a list of helper commands and their descriptions.
+func (hl *HelperLoader) Usage() string {
+	names := NameTransformerFunc(func(path *Path) string {
+		for i := range hl.settings {
+			if hl.settings[i].Path == path {
+				return hl.settings[i].Tag.Get("helper")
+			}
+		}
+		return displayKey(path)
+	})
+	return keyUsage("Helper Commands", hl.settings, names)
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHelperLoader(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("DB"))
+	var password, user, name string
+	var port int
+
+	t.Run("load", func(t *testing.T) {
+		loader := new(HelperLoader)
+		loader.Init([]Setting{
+			newTestSetting(node, "Password", `helper:"echo secret"`, &password),
+			newTestSetting(node, "User", `helper:"cat"`, &user),
+			newTestSetting(node, "Name", "", &name),
+		})
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if password != "secret" {
+			t.Errorf("unexpected value %q for password", password)
+		}
+		if user != "db.user" {
+			t.Errorf("unexpected value %q for user", user)
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "  echo secret=string\n") {
+			t.Errorf("unexpected usage %q", usage)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		loader := &HelperLoader{Timeout: 50 * time.Millisecond}
+		loader.Init([]Setting{
+			newTestSetting(node, "Password", `helper:"ls /nonexistent/path"`, &password),
+			newTestSetting(node, "Port", `helper:"echo x" max:"10"`, &port),
+			newTestSetting(node, "User", `helper:"sleep 1"`, &user),
+			newTestSetting(node, "Name", `helper:"/nonexistent/helper"`, &name),
+		})
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 4 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if msg := (*errs)[0].Error(); !strings.Contains(msg, "exit status") || !strings.Contains(msg, "/nonexistent/path") {
+			t.Errorf("unexpected error %s", msg)
+		}
+		if _, ok := (*errs)[1].(*ConversionError); !ok {
+			t.Errorf("unexpected error %s", (*errs)[1])
+		}
+		if msg := (*errs)[2].Error(); !strings.Contains(msg, "timed out") {
+			t.Errorf("unexpected error %s", msg)
+		}
+	})
+
+	t.Run("script timeout", func(t *testing.T) {
+		// The shell waits for its own child, which still holds the output
+		// open when the shell is killed.
+		script, cleanup := writeTempFile(t, "helper.sh", "#!/bin/sh\nsleep 3\necho x\n")
+		defer cleanup()
+		if err := os.Chmod(script, 0700); err != nil {
+			t.Fatalf("failed making script executable: %s", err)
+		}
+		loader := &HelperLoader{Timeout: 50 * time.Millisecond}
+		loader.Init([]Setting{newTestSetting(node, "User", `helper:"`+script+`"`, &user)})
+		start := time.Now()
+		err := loader.Load()
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("unexpected error %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("helper timed out after %s", elapsed)
+		}
+	})
+}
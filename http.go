@@ -0,0 +1,222 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+/*
+HTTPLoader implements a Loader type to parse settings from a document fetched
+from an HTTP or HTTPS URL.
+
+The document is decoded with a Decoder, chosen by the media type given in the
+Content-Type header of the response, or, if no Decoder is known for the media
+type, by the extension of the URL's path. The decoded document is then used to
+set values as described for FileLoader. Documents of type application/json are
+decoded as JSON; application/yaml, application/x-yaml and text/yaml as YAML;
+and application/toml as TOML.
+
+Each request has a timeout, and failed requests are retried with an
+exponential backoff. Requests fail if they can not be completed, or if the
+server responds with a 5xx or 429 status code; any other status code except 200
+or 304 is an error, and is not retried.
+
+If the server provides an ETag header, the response is cached, and subsequent
+calls to Load send it in an If-None-Match header. A 304 (Not Modified) response
+causes the cached document to be used.
+
+The URL can itself be configured by a bootstrap setting, as described for
+Config.Load. For example,
+	remote := new(config.HTTPLoader)
+	remote.Header = http.Header{"Authorization": {"Bearer " + token}}
+	config.DefaultConfig.SetLoaders(config.Loaders{
+		remote, new(config.EnvLoader), new(config.FlagLoader),
+	})
+	config.Var(&remote.URL, `bootstrap:"true" from:"env,flag"`, "config", "url")
+lets the URL be given with -config-url or CONFIG_URL.
+
+The zero value is ready to use, but Load does nothing until URL is set.
+*/
+type HTTPLoader struct {
+	// URL is the URL of the document.
+	URL string
+	// Header contains additional headers to send with each request, such as
+	// those needed for authorization.
+	Header http.Header
+	// Client is used to make requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Timeout limits the time taken by each request. If zero, a timeout of
+	// 10 seconds is used.
+	Timeout time.Duration
+	// Retries is the number of times to retry a failed request.
+	Retries int
+	// Backoff is the time to wait before the first retry. The time is
+	// doubled before each subsequent retry. If zero, 1 second is used.
+	Backoff time.Duration
+	// Decoders is used to find a Decoder by file extension. If nil, the
+	// DefaultDecoderRegistry is used.
+	Decoders DecoderRegistry
+	settings []Setting
+	etag     string
+	cached   []byte
+	decoder  Decoder
+}
+
+// Name returns the name of the loader. It is always "http".
+func (*HTTPLoader) Name() string {
+	return "http"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. It panics if two settings would be matched by
+the same key.
+*/
+func (hl *HTTPLoader) Init(settings []Setting) {
+	newKeyIndex(settings)
+	hl.settings = settings
+}
+
+/*
+Load attempts to fetch a document from hl.URL, and to parse the configured
+settings from it.
+
+The returned error, if non-nil, will be of type Errors. It will contain either
+a single error from fetching the document, or a *FileError if the document
+could not be parsed, or errors from setting values, as described for
+FileLoader.Load. The URL is used as the file name of any *FileError.
+*/
+func (hl *HTTPLoader) Load() error {
+	if hl.URL == "" {
+		return nil
+	}
+	data, decoder, err := hl.fetch()
+	if err != nil {
+		return &Errors{err}
+	}
+	tree, err := decodeFile(decoder, hl.URL, data)
+	if err != nil {
+		return &Errors{err}
+	}
+	return applyFile(newKeyIndex(hl.settings), hl.URL, tree)
+}
+
+/*
+fetch requests hl.URL, retrying on failure.
+
+It returns the body of the response, and a Decoder to parse it.
+*/
+func (hl *HTTPLoader) fetch() ([]byte, Decoder, error) {
+	backoff := hl.Backoff
+	if backoff == 0 {
+		backoff = time.Second
+	}
+	for retry := 0; ; retry++ {
+		data, decoder, temporary, err := hl.request()
+		if err == nil || !temporary || retry >= hl.Retries {
+			return data, decoder, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+/*
+request makes a single request for hl.URL.
+
+If the request fails, temporary indicates whether it may be retried.
+*/
+func (hl *HTTPLoader) request() (data []byte, decoder Decoder, temporary bool, err error) {
+	req, err := http.NewRequest(http.MethodGet, hl.URL, nil)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for k, v := range hl.Header {
+		req.Header[k] = v
+	}
+	if hl.etag != "" && hl.cached != nil {
+		req.Header.Set("If-None-Match", hl.etag)
+	}
+	timeout := hl.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client := hl.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, true, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hl.cached != nil:
+		return hl.cached, hl.decoder, false, nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return nil, nil, true, fmt.Errorf("%s: %s", hl.URL, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, false, fmt.Errorf("%s: %s", hl.URL, resp.Status)
+	}
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	decoder, err = hl.getDecoder(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	hl.etag, hl.cached, hl.decoder = resp.Header.Get("ETag"), nil, decoder
+	if hl.etag != "" {
+		hl.cached = data
+	}
+	return data, decoder, false, nil
+}
+
+// mediaTypeExtensions maps media types to the extensions of their Decoders.
+var mediaTypeExtensions = map[string]string{
+	"application/json":   ".json",
+	"application/yaml":   ".yaml",
+	"application/x-yaml": ".yaml",
+	"text/yaml":          ".yaml",
+	"text/x-yaml":        ".yaml",
+	"application/toml":   ".toml",
+}
+
+// getDecoder returns a Decoder for contentType or, failing that, hl.URL.
+func (hl *HTTPLoader) getDecoder(contentType string) (Decoder, error) {
+	reg := hl.Decoders
+	if reg == nil {
+		reg = DefaultDecoderRegistry
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if ext, ok := mediaTypeExtensions[mediaType]; ok {
+			if decoder := reg.GetDecoder(ext); decoder != nil {
+				return decoder, nil
+			}
+		}
+	}
+	if u, err := url.Parse(hl.URL); err == nil {
+		if decoder := reg.GetDecoder(path.Base(u.Path)); decoder != nil {
+			return decoder, nil
+		}
+	}
+	return nil, &FileError{
+		Filename: hl.URL,
+		Err:      fmt.Errorf("no decoder for content type %q", contentType),
+	}
+}
+
+// Usage returns a string with a list of document keys and their descriptions.
+func (hl *HTTPLoader) Usage() string {
+	return keyUsage("Remote Configuration "+hl.URL, hl.settings, nil)
+}
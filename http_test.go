@@ -0,0 +1,145 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHTTPLoader(t *testing.T) {
+	root := NewRootPath("")
+	var name string
+	var port int
+	settings := settings{
+		{
+			Path: root.AddPath(root.NewPath("Name")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&name).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Port")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&port).Elem(), `max:"65535"`,
+			),
+		},
+	}
+
+	var requests, failures int
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/config":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"name": "remote", "port": 8080}`))
+		case "/config.yaml":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("name: yaml\nport: 65536\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newLoader := func(path string) *HTTPLoader {
+		loader := &HTTPLoader{
+			URL:     server.URL + path,
+			Header:  http.Header{"Authorization": {"Bearer token"}},
+			Retries: 2,
+			Backoff: time.Millisecond,
+		}
+		loader.Init(settings)
+		return loader
+	}
+
+	t.Run("load", func(t *testing.T) {
+		requests, failures, status = 0, 2, 0
+		loader := newLoader("/config")
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if name != "remote" || port != 8080 {
+			t.Errorf("unexpected values %q, %d", name, port)
+		}
+		if requests != 3 {
+			t.Errorf("unexpected number of requests %d", requests)
+		}
+
+		name, port = "", 0
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if name != "remote" || port != 8080 {
+			t.Errorf("unexpected values %q, %d from cached document", name, port)
+		}
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		requests, failures, status = 0, 3, 0
+		if err := newLoader("/config").Load(); err == nil {
+			t.Error("failed requests did not return an error")
+		}
+		if requests != 3 {
+			t.Errorf("unexpected number of requests %d", requests)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		requests, failures, status = 0, 0, http.StatusNotFound
+		if err := newLoader("/config").Load(); err == nil {
+			t.Error("not found did not return an error")
+		}
+		if requests != 1 {
+			t.Errorf("unexpected number of requests %d", requests)
+		}
+	})
+
+	t.Run("extension", func(t *testing.T) {
+		requests, failures, status = 0, 0, 0
+		loader := newLoader("/config.yaml")
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if fe, ok := (*errs)[0].(*FileError); !ok || fe.Filename != loader.URL || fe.Line != 2 {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+		if name != "yaml" {
+			t.Errorf("unexpected value %q for name", name)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer slow.Close()
+		loader := &HTTPLoader{URL: slow.URL, Timeout: 10 * time.Millisecond}
+		loader.Init(settings)
+		if err := loader.Load(); err == nil {
+			t.Error("slow request did not return an error")
+		}
+	})
+}
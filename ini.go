@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+INIDecoder implements a Decoder for INI files.
+
+Section headers correspond to the prefixes of nested structs, and keys to the
+names of individual settings. Dots within a section name separate nested
+prefixes, so that
+	opts := struct {
+		Server struct {
+			HTTP struct {
+				Listen []string
+			}
+			Name string
+		}
+	}{}
+can be set from
+	[server]
+	name = www
+
+	[server.http]
+	listen = 127.0.0.1:80
+	listen = 127.0.0.1:8080
+Keys are separated from values with either "=" or ":", and are matched as
+described for FileLoader. Keys that appear before any section header
+correspond to top-level settings. Lines starting with ";" or "#" are comments.
+Values are trimmed of surrounding whitespace, and may be enclosed in double
+quotes, in which case they are unquoted with strconv.Unquote.
+
+All values are passed to Setter.Set. If a key is repeated, each value is set in
+turn, so that the values are appended to slice types, subject to the `append`
+and `sep` struct tags.
+
+It is registered in DefaultDecoderRegistry for the ".ini" extension.
+*/
+type INIDecoder struct{}
+
+// Extensions returns the extensions for INI files. It is always {".ini"}.
+func (INIDecoder) Extensions() []string {
+	return []string{".ini"}
+}
+
+/*
+Decode parses an INI document.
+
+Each value is wrapped in a *Located value. Repeated keys result in a
+[]interface{}. An error, if returned, will be of type *FileError.
+*/
+func (INIDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	section := tree
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "", trimmed[0] == ';', trimmed[0] == '#':
+			continue
+		case trimmed[0] == '[':
+			if trimmed[len(trimmed)-1] != ']' {
+				return nil, &FileError{
+					Line:   lineNo,
+					Column: strings.Index(line, "[") + 1,
+					Err:    errors.New("unterminated section header"),
+				}
+			}
+			section = tree
+			for _, name := range strings.Split(trimmed[1:len(trimmed)-1], ".") {
+				name = strings.TrimSpace(name)
+				child, ok := section[name].(map[string]interface{})
+				if !ok {
+					child = make(map[string]interface{})
+					section[name] = child
+				}
+				section = child
+			}
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, &FileError{
+				Line:   lineNo,
+				Column: len(line) - len(strings.TrimLeft(line, " \t")) + 1,
+				Err:    errors.New(`expected "=" or ":" after key`),
+			}
+		}
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+		column := i + 2 + len(line[i+1:]) - len(strings.TrimLeft(line[i+1:], " \t"))
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, &FileError{Line: lineNo, Column: column, Err: err}
+			}
+			value = unquoted
+		}
+		loc := &Located{Value: value, Line: lineNo, Column: column}
+		switch existing := section[key].(type) {
+		case nil:
+			section[key] = loc
+		case *Located:
+			section[key] = []interface{}{existing, loc}
+		case []interface{}:
+			section[key] = append(existing, loc)
+		default:
+			return nil, &FileError{
+				Line:   lineNo,
+				Column: column,
+				Err:    errors.New("key " + key + " is also a section name"),
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &FileError{Err: err}
+	}
+	return tree, nil
+}
+
+func init() {
+	DefaultDecoderRegistry.Add(INIDecoder{})
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestINIDecoder(t *testing.T) {
+	type options struct {
+		Name   string
+		Server struct {
+			HTTP struct {
+				Listen  []string
+				Methods []string `sep:","`
+				Hosts   []string `append:"false"`
+			}
+			Timeout int `max:"60"`
+		}
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.ini", `
+; global settings
+name = "my server"
+
+[server]
+timeout: 30
+
+# nested section
+[server.http]
+listen = 127.0.0.1:80
+listen = 127.0.0.1:8080
+methods = GET,POST
+methods = PUT
+hosts = a
+hosts = b
+`)
+		defer cleanup()
+		opts := options{}
+		opts.Server.HTTP.Hosts = []string{"default"}
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		if err := c.Configure(&opts); err != nil {
+			t.Fatalf("unexpected error loading %s: %s", filename, err)
+		}
+		if opts.Name != "my server" {
+			t.Errorf("unexpected value %q for name", opts.Name)
+		}
+		if opts.Server.Timeout != 30 {
+			t.Errorf("unexpected value %d for timeout", opts.Server.Timeout)
+		}
+		http := opts.Server.HTTP
+		if len(http.Listen) != 2 || http.Listen[0] != "127.0.0.1:80" || http.Listen[1] != "127.0.0.1:8080" {
+			t.Errorf("unexpected value %s for listen", http.Listen)
+		}
+		if len(http.Methods) != 3 || http.Methods[0] != "GET" || http.Methods[2] != "PUT" {
+			t.Errorf("unexpected value %s for methods", http.Methods)
+		}
+		if len(http.Hosts) != 2 || http.Hosts[0] != "a" || http.Hosts[1] != "b" {
+			t.Errorf("unexpected value %s for hosts", http.Hosts)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.ini", "[server]\ntimeout = 61\n")
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		err := c.Configure(&opts)
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		fe, ok := (*errs)[0].(*FileError)
+		if !ok || fe.Filename != filename || fe.Line != 2 || fe.Column != 11 {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		for _, content := range []string{"[server\n", "name\n"} {
+			filename, cleanup := writeTempFile(t, "config.ini", content)
+			var opts options
+			var c Config
+			c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+			err := c.Configure(&opts)
+			cleanup()
+			errs, ok := err.(*Errors)
+			if !ok || len(*errs) != 1 {
+				t.Fatalf("unexpected error %v loading %q", err, content)
+			}
+			if fe, ok := (*errs)[0].(*FileError); !ok || fe.Line != 1 {
+				t.Errorf("unexpected error %s", (*errs)[0])
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+/*
+JSONDecoder implements a Decoder for JSON files.
+
+The file must contain a single JSON object. Nested objects correspond to the
+prefixes of nested structs, and keys to the names of individual settings, as
+described for FileLoader.
+
+Strings are set with Setter.Set, booleans with Setter.SetBool and numbers with
+Setter.SetInt, Setter.SetUint or Setter.SetFloat, depending on their value.
+Each element of an array is set in turn, so arrays are appended to slices.
+Arrays of objects can be used to set slices of structs. Null values are
+ignored.
+
+It is registered in DefaultDecoderRegistry for the ".json" extension.
+*/
+type JSONDecoder struct{}
+
+// Extensions returns the extensions for JSON files. It is always {".json"}.
+func (JSONDecoder) Extensions() []string {
+	return []string{".json"}
+}
+
+/*
+Decode parses a JSON document.
+
+Numbers in the returned tree are of type json.Number. An error, if returned,
+will be of type *FileError.
+*/
+func (JSONDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	var tree map[string]interface{}
+	r := bytes.NewReader(data)
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		fe := &FileError{Err: err}
+		switch err := err.(type) {
+		case *json.SyntaxError:
+			fe.Line, fe.Column = offsetPosition(data, err.Offset)
+		case *json.UnmarshalTypeError:
+			fe.Line, fe.Column = offsetPosition(data, err.Offset)
+		}
+		return nil, fe
+	}
+	// Nothing but whitespace may follow the object.
+	rest, _ := ioutil.ReadAll(io.MultiReader(dec.Buffered(), r))
+	if extra := bytes.TrimLeft(rest, " \t\r\n"); len(extra) != 0 {
+		line, column := offsetPosition(data, int64(len(data)-len(extra)+1))
+		return nil, &FileError{
+			Line:   line,
+			Column: column,
+			Err:    errors.New("unexpected data after top-level object"),
+		}
+	}
+	return tree, nil
+}
+
+/*
+offsetPosition converts an offset reported by encoding/json, which is the number
+of bytes read before an error, to the line and column of the last byte read.
+*/
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[:offset]
+	line = bytes.Count(data, []byte("\n")) + 1
+	column = len(data) - bytes.LastIndexByte(data, '\n') - 1
+	if column == 0 && len(data) > 0 {
+		// The last byte read was a newline.
+		line--
+		column = len(data) - bytes.LastIndexByte(data[:len(data)-1], '\n') - 1
+	}
+	return line, column
+}
+
+func init() {
+	DefaultDecoderRegistry.Add(JSONDecoder{})
+}
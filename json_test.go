@@ -0,0 +1,132 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONDecoder(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("HTMLParser"))
+	var duration []time.Duration
+	var iterations int
+	var ratio float64
+	var name *string
+	var verbose bool
+	settings := settings{
+		{
+			Path: node.AddPath(node.NewPath("Durations")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&duration).Elem(), "",
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Iterations")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&iterations).Elem(), `max:"10"`,
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Name")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&name).Elem(), "",
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Ratio")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&ratio).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Verbose")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&verbose).Elem(), "",
+			),
+		},
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.json", `{
+			"html_parser": {
+				"durations": ["1s", 2],
+				"iterations": 3,
+				"name": "mytest",
+				"ratio": 0.5,
+				"unknown": {"x": 1}
+			},
+			"Verbose": true
+		}`)
+		defer cleanup()
+		loader := &FileLoader{Filenames: []string{filename}}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error loading %s: %s", filename, err)
+		}
+		if len(duration) != 2 || duration[0] != time.Second || duration[1] != 2*time.Second {
+			t.Errorf("unexpected value %s for durations", duration)
+		}
+		if iterations != 3 {
+			t.Errorf("unexpected value %d for iterations", iterations)
+		}
+		if name == nil || *name != "mytest" {
+			t.Errorf("unexpected value %v for name", name)
+		}
+		if ratio != 0.5 {
+			t.Errorf("unexpected value %f for ratio", ratio)
+		}
+		if !verbose {
+			t.Error("verbose was not set")
+		}
+	})
+
+	t.Run("dotted keys", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.json", `{"HTMLParser.Iterations": 4}`)
+		defer cleanup()
+		loader := &FileLoader{Filenames: []string{filename}}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error loading %s: %s", filename, err)
+		}
+		if iterations != 4 {
+			t.Errorf("unexpected value %d for iterations", iterations)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.json", `{"html_parser": {"iterations": 11}}`)
+		defer cleanup()
+		loader := &FileLoader{Filenames: []string{filename}}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		if ve, ok := (*errs)[0].(*ValidationError); !ok || ve.Path != settings[1].Path {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+		if iterations != 4 {
+			t.Errorf("unexpected value %d for iterations", iterations)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := JSONDecoder{}.Decode([]byte("{\n  \"name\": x\n}"))
+		if fe, ok := err.(*FileError); !ok || fe.Line != 2 || fe.Column != 11 {
+			t.Errorf("unexpected error %v decoding invalid JSON", err)
+		}
+		_, err = JSONDecoder{}.Decode([]byte("[]"))
+		if fe, ok := err.(*FileError); !ok || fe.Line != 1 {
+			t.Errorf("unexpected error %v decoding JSON array", err)
+		}
+		_, err = JSONDecoder{}.Decode([]byte("{\"port\": 1}\n\n {} trailing"))
+		if fe, ok := err.(*FileError); !ok || fe.Line != 3 || fe.Column != 2 {
+			t.Errorf("unexpected error %v decoding JSON with trailing data", err)
+		}
+		if _, err = (JSONDecoder{}).Decode([]byte("{\"port\": 1}\n")); err != nil {
+			t.Errorf("unexpected error %s decoding JSON with trailing newline", err)
+		}
+	})
+}
@@ -153,6 +153,15 @@ func FriendlyTypeName(x interface{}) string {
 
 // The below is borrowed from Go's flag.go.
 func isZeroValue(value flag.Value) bool {
+	for {
+		if ds, ok := value.(*discardSetter); ok {
+			value = ds.Setter
+		} else if cf, ok := value.(*countFlag); ok {
+			value = cf.Setter
+		} else {
+			break
+		}
+	}
 	typ := reflect.TypeOf(value)
 	var z reflect.Value
 	if typ.Kind() == reflect.Ptr {
@@ -1,11 +1,26 @@
 package config
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 )
 
+/*
+newTestSetting returns a Setting for the value pointed to by p, with a path
+named name below np, and with the given struct tag.
+*/
+func newTestSetting(np *NodePath, name, tag string, p interface{}) Setting {
+	return Setting{
+		Path: np.NewPath(name),
+		Tag:  reflect.StructTag(tag),
+		Setter: DefaultSetterRegistry.GetSetter(
+			reflect.ValueOf(p).Elem(), reflect.StructTag(tag),
+		),
+	}
+}
+
 func TestSplitName(t *testing.T) {
 	slicesEqual := func(a, b []string) bool {
 		for len(a) != 0 && len(b) != 0 {
@@ -0,0 +1,66 @@
+package config
+
+/*
+MapLoader implements a Loader type to set values from a map.
+
+It is useful for tests, and for overriding values from within an application.
+Keys are the dotted paths of settings, and are matched as described for
+FileLoader:
+	loader := &config.MapLoader{Values: map[string]interface{}{
+		"auth.user":   "user1",
+		"verbose":     true,
+		"timeout":     "5s",
+		"html_parser": map[string]interface{}{"iterations": 3},
+	}}
+Values of type map[string]interface{} or map[string]string may also be nested
+as above. Strings are passed to Setter.Set, and other values to the typed
+methods of Setter, as described for Decoder. Where all values are strings,
+Strings can be used instead:
+	loader := &config.MapLoader{Strings: map[string]string{"auth.user": "user1"}}
+
+The zero value is ready to use, but Load does nothing until Values or Strings
+is set.
+*/
+type MapLoader struct {
+	// Values maps keys to values of any type.
+	Values map[string]interface{}
+	// Strings maps keys to string values. Values are set from Strings first,
+	// so Values takes precedence if a key is in both.
+	Strings  map[string]string
+	settings []Setting
+}
+
+// Name returns the name of the loader. It is always "map".
+func (*MapLoader) Name() string {
+	return "map"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. It panics if two settings would be matched by
+the same key.
+*/
+func (ml *MapLoader) Init(settings []Setting) {
+	newKeyIndex(settings)
+	ml.settings = settings
+}
+
+/*
+Load sets the configured settings from ml.Strings and ml.Values.
+
+The returned error, if non-nil, will be of type Errors. Each element will be of
+type *ConversionError or *ValidationError.
+*/
+func (ml *MapLoader) Load() error {
+	index := newKeyIndex(ml.settings)
+	var errs Errors
+	errs.Append(index.apply(stringTree(ml.Strings)))
+	errs.Append(index.apply(ml.Values))
+	return errs.AsError()
+}
+
+// Usage returns a string with a list of keys and their descriptions.
+func (ml *MapLoader) Usage() string {
+	return keyUsage("Values", ml.settings, nil)
+}
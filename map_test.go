@@ -0,0 +1,83 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMapLoader(t *testing.T) {
+	type options struct {
+		Auth struct {
+			User string
+		}
+		Timeout time.Duration
+		Retries int `max:"5"`
+		Ratio   float64
+		Verbose bool
+		Tags    []string
+	}
+
+	t.Run("interface values", func(t *testing.T) {
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&MapLoader{Values: map[string]interface{}{
+			"auth.user": "user1",
+			"timeout":   "5s",
+			"retries":   3,
+			"ratio":     0.5,
+			"verbose":   true,
+			"tags":      []interface{}{"a", "b"},
+		}}})
+		if err := c.Configure(&opts); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		switch {
+		case opts.Auth.User != "user1":
+			t.Errorf("unexpected value %q for user", opts.Auth.User)
+		case opts.Timeout != 5*time.Second:
+			t.Errorf("unexpected value %s for timeout", opts.Timeout)
+		case opts.Retries != 3:
+			t.Errorf("unexpected value %d for retries", opts.Retries)
+		case opts.Ratio != 0.5:
+			t.Errorf("unexpected value %f for ratio", opts.Ratio)
+		case !opts.Verbose:
+			t.Error("verbose was not set")
+		case len(opts.Tags) != 2:
+			t.Errorf("unexpected value %s for tags", opts.Tags)
+		}
+	})
+
+	t.Run("string values", func(t *testing.T) {
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{
+			&MapLoader{Strings: map[string]string{"auth.user": "user1", "retries": "1"}},
+			&MapLoader{Strings: map[string]string{"retries": "6"}},
+		})
+		err := c.Configure(&opts)
+		if errs, ok := err.(*Errors); !ok || len(*errs) != 1 {
+			t.Errorf("unexpected error %v", err)
+		}
+		if opts.Auth.User != "user1" || opts.Retries != 1 {
+			t.Errorf("unexpected values %q, %d", opts.Auth.User, opts.Retries)
+		}
+	})
+
+	t.Run("nested strings", func(t *testing.T) {
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&MapLoader{
+			Values: map[string]interface{}{
+				"auth":    map[string]string{"user": "user2"},
+				"retries": 2,
+			},
+			Strings: map[string]string{"auth.user": "user1", "retries": "1"},
+		}})
+		if err := c.Configure(&opts); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if opts.Auth.User != "user2" || opts.Retries != 2 {
+			t.Errorf("unexpected values %q, %d", opts.Auth.User, opts.Retries)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strings"
+)
+
+/*
+NameTransformer is an interface for generating the names used by a loader,
+such as environment variable names or command line flags, from the path of a
+setting.
+
+Loaders which accept a NameTransformer include EnvLoader, FlagLoader, DirLoader
+and CredentialsLoader. The package provides transformers for common naming
+schemes, and NameTransformerFunc can be used to provide a custom scheme.
+*/
+type NameTransformer interface {
+	// TransformName returns the name for path.
+	TransformName(path *Path) string
+}
+
+// NameTransformerFunc is a function type implementing NameTransformer.
+type NameTransformerFunc func(path *Path) string
+
+// TransformName calls f(path).
+func (f NameTransformerFunc) TransformName(path *Path) string {
+	return f(path)
+}
+
+/*
+transformWords returns a NameTransformer which splits each element of a path
+into words with SplitName, and trims any dashes or underscores from each word.
+Words are then passed to word, with their index within the path, and joined
+with wordSep within each element and with elementSep between elements.
+*/
+func transformWords(word func(i int, w string) string, wordSep, elementSep string) NameTransformer {
+	return NameTransformerFunc(func(path *Path) string {
+		elements := path.Elements()
+		n := 0
+		for i := range elements {
+			parts := SplitName(elements[i])
+			for j := range parts {
+				parts[j] = word(n, strings.Trim(parts[j], "-_"))
+				n++
+			}
+			elements[i] = strings.Join(parts, wordSep)
+		}
+		return strings.Join(elements, elementSep)
+	})
+}
+
+var (
+	// EnvNames generates the names used by EnvLoader by default, with words
+	// capitalized and joined with underscores, e.g. HTML_PARSER_ELEMENT_IDS.
+	EnvNames = transformWords(
+		func(_ int, w string) string { return strings.ToUpper(w) }, "_", "_",
+	)
+
+	// FlagNames generates the names used by FlagLoader by default, with words
+	// lowercased and joined with dashes, e.g. html-parser-element-ids.
+	FlagNames = transformWords(
+		func(_ int, w string) string { return strings.ToLower(w) }, "-", "-",
+	)
+
+	// SnakeCaseNames generates names with words lowercased and joined with
+	// underscores, e.g. html_parser_element_ids.
+	SnakeCaseNames = transformWords(
+		func(_ int, w string) string { return strings.ToLower(w) }, "_", "_",
+	)
+
+	// CamelCaseNames generates names with words joined together, the first
+	// lowercased and the remainder capitalized, e.g. htmlParserElementIds.
+	CamelCaseNames = transformWords(
+		func(i int, w string) string {
+			w = strings.ToLower(w)
+			if i == 0 || w == "" {
+				return w
+			}
+			return strings.ToUpper(w[:1]) + w[1:]
+		}, "", "",
+	)
+
+	// DottedNames generates names with the words of each element lowercased
+	// and joined with underscores, and elements joined with dots, e.g.
+	// html_parser.element_ids. These are the names shown in the usage of
+	// loaders for configuration files.
+	DottedNames = transformWords(
+		func(_ int, w string) string { return strings.ToLower(w) }, "_", ".",
+	)
+
+	// AsWrittenNames generates names from the elements of a path as written,
+	// joined with dots, e.g. HTMLParser.ElementIDs.
+	AsWrittenNames NameTransformer = NameTransformerFunc(func(path *Path) string {
+		return strings.Join(path.Elements(), ".")
+	})
+)
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNameTransformers(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("HTMLParser"))
+	path := node.NewPath("ElementIDs")
+	for _, tc := range []struct {
+		name     string
+		names    NameTransformer
+		expected string
+	}{
+		{"env", EnvNames, "HTML_PARSER_ELEMENT_IDS"},
+		{"flag", FlagNames, "html-parser-element-ids"},
+		{"snake case", SnakeCaseNames, "html_parser_element_ids"},
+		{"camel case", CamelCaseNames, "htmlParserElementIds"},
+		{"dotted", DottedNames, "html_parser.element_ids"},
+		{"as written", AsWrittenNames, "HTMLParser.ElementIDs"},
+	} {
+		if name := tc.names.TransformName(path); name != tc.expected {
+			t.Errorf("unexpected %s name %s", tc.name, name)
+		}
+	}
+
+	var x int
+	settings := []Setting{{
+		Path: path,
+		Setter: DefaultSetterRegistry.GetSetter(
+			reflect.ValueOf(&x).Elem(), "",
+		),
+	}}
+
+	t.Run("env", func(t *testing.T) {
+		loader := &EnvLoader{
+			Names:  SnakeCaseNames,
+			Prefix: "app_",
+			Lookup: EnvironLookup([]string{"app_html_parser_element_ids=1"}),
+		}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error %s", err)
+		}
+		if x != 1 {
+			t.Errorf("unexpected value %d", x)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		loader := &FlagLoader{
+			Names:     CamelCaseNames,
+			Arguments: []string{"-htmlParserElementIds", "2"},
+		}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error %s", err)
+		}
+		if x != 2 {
+			t.Errorf("unexpected value %d", x)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+/*
+PropertiesDecoder implements a Decoder for Java .properties files.
+
+Keys are split on dots to match the prefixes of nested structs, and the final
+part of the key matches the name of an individual setting, so that
+	opts := struct {
+		DB struct {
+			Pool struct {
+				MaxSize int
+			}
+		}
+	}{}
+can be set from
+	db.pool.max-size = 10
+Each part of a key is matched as described for FileLoader.
+
+The file format follows that of java.util.Properties. Keys are separated from
+values by "=", ":" or whitespace. Lines whose first non-whitespace character is
+"#" or "!" are comments. A line ending in a backslash is continued on the next
+line, with leading whitespace on the continuation line ignored. Keys and values
+may contain the escape sequences \t, \n, \f, \r and \uXXXX, and any other
+character preceded by a backslash stands for itself. If a key is repeated, the
+last value is used.
+
+All values are passed to Setter.Set.
+
+It is registered in DefaultDecoderRegistry for the ".properties" extension.
+*/
+type PropertiesDecoder struct{}
+
+// Extensions returns the extensions for properties files, {".properties"}.
+func (PropertiesDecoder) Extensions() []string {
+	return []string{".properties"}
+}
+
+/*
+Decode parses a .properties document.
+
+The returned map has dotted keys, each with a *Located value giving the line
+on which the key started. An error, if returned, will be of type *FileError.
+*/
+func (PropertiesDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	lines := strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// Join continuation lines. A line is continued if it ends with an odd
+		// number of backslashes.
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+
+		// The key ends at the first unescaped separator or whitespace.
+		end := len(line)
+		for j := 0; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+			} else if strings.IndexByte("=: \t\f", line[j]) >= 0 {
+				end = j
+				break
+			}
+		}
+		key, value := line[:end], strings.TrimLeft(line[end:], " \t\f")
+		if value != "" && (value[0] == '=' || value[0] == ':') {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+
+		var err error
+		if key, err = unescapeProperty(key); err != nil {
+			return nil, &FileError{Line: lineNo, Err: err}
+		}
+		if value, err = unescapeProperty(value); err != nil {
+			return nil, &FileError{Line: lineNo, Err: err}
+		}
+		tree[key] = &Located{Value: value, Line: lineNo}
+	}
+	return tree, nil
+}
+
+// continued returns true if line ends with an odd number of backslashes.
+func continued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// unescapeProperty replaces escape sequences in a key or value.
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errors.New(`malformed \uXXXX escape`)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", errors.New(`malformed \uXXXX escape`)
+			}
+			i += 4
+			// Characters outside the Basic Multilingual Plane are escaped
+			// as UTF-16 surrogate pairs.
+			if utf16.IsSurrogate(rune(r)) && i+7 <= len(s) && s[i+1:i+3] == `\u` {
+				if r2, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+					if dec := utf16.DecodeRune(rune(r), rune(r2)); dec != unicode.ReplacementChar {
+						b.WriteRune(dec)
+						i += 6
+						continue
+					}
+				}
+			}
+			b.WriteRune(rune(r))
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func init() {
+	DefaultDecoderRegistry.Add(PropertiesDecoder{})
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestPropertiesDecoder(t *testing.T) {
+	type options struct {
+		DB struct {
+			Pool struct {
+				MaxSize int `max:"100"`
+			}
+			URL string
+		}
+		Greeting string
+		Path     string
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "app.properties", `
+# database settings
+! also a comment
+db.pool.max-size = 10
+db.url:jdbc:postgresql://localhost/app
+greeting   Hello, \
+           World \u00e9\uD83D\uDE00
+path = C:\\temp\\
+unknown.key = x
+`)
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		if err := c.Configure(&opts); err != nil {
+			t.Fatalf("unexpected error loading %s: %s", filename, err)
+		}
+		if opts.DB.Pool.MaxSize != 10 {
+			t.Errorf("unexpected value %d for max size", opts.DB.Pool.MaxSize)
+		}
+		if opts.DB.URL != "jdbc:postgresql://localhost/app" {
+			t.Errorf("unexpected value %q for URL", opts.DB.URL)
+		}
+		if opts.Greeting != "Hello, World \u00e9\U0001F600" {
+			t.Errorf("unexpected value %q for greeting", opts.Greeting)
+		}
+		if opts.Path != `C:\temp\` {
+			t.Errorf("unexpected value %q for path", opts.Path)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "app.properties", "\ndb.pool.maxSize=101\n")
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		err := c.Configure(&opts)
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		if fe, ok := (*errs)[0].(*FileError); !ok || fe.Filename != filename || fe.Line != 2 {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "app.properties", "a=1\nb=\\u12\n")
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		err := c.Configure(&opts)
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		if fe, ok := (*errs)[0].(*FileError); !ok || fe.Line != 2 {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+}
@@ -11,8 +11,8 @@ Setter is an interface which is used to parse, validate and set values of a
 specific type.
 
 Note that SetInt, SetUint, SetFloat and SetBool are not used for the default
-loaders, but may be used by custom Loader implementations or in future
-features.
+loaders, but are used by loaders for typed documents such as FileLoader, and may
+be used by custom Loader implementations.
 */
 type Setter interface {
 	// String should return a descriptive string for the current value
@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"sort"
+	"strconv"
 )
 
 /*
@@ -32,3 +33,59 @@ func (s *settings) add(setting Setting) {
 	copy((*s)[i+1:], (*s)[i:])
 	(*s)[i] = setting
 }
+
+// isBootstrap returns true if the setting has the `bootstrap:"true"` tag.
+func (s *Setting) isBootstrap() bool {
+	b, _ := strconv.ParseBool(s.Tag.Get("bootstrap"))
+	return b
+}
+
+func (s settings) hasBootstrap() bool {
+	for i := range s {
+		if s[i].isBootstrap() {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+discardSetter wraps a Setter, discarding any values that are set.
+
+It is used for settings which should be recognized by a loader, but not set,
+during one phase of Config.Load.
+*/
+type discardSetter struct {
+	Setter
+}
+
+func (*discardSetter) Set(string) error {
+	return nil
+}
+
+func (*discardSetter) SetInt(int64) error {
+	return nil
+}
+
+func (*discardSetter) SetUint(uint64) error {
+	return nil
+}
+
+func (*discardSetter) SetFloat(float64) error {
+	return nil
+}
+
+func (*discardSetter) SetBool(bool) error {
+	return nil
+}
+
+func (*discardSetter) setTable(*Path, map[string]interface{}) error {
+	return nil
+}
+
+// IsBoolFlag returns the result of the wrapped Setter's IsBoolFlag method, if
+// it has one, so that flags are parsed the same way in each phase.
+func (ds *discardSetter) IsBoolFlag() bool {
+	ibf, ok := ds.Setter.(interface{ IsBoolFlag() bool })
+	return ok && ibf.IsBoolFlag()
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+/*
+tableSetter is implemented by Setters that can be set from a table of values,
+such as an object in a JSON document.
+*/
+type tableSetter interface {
+	setTable(path *Path, table map[string]interface{}) error
+}
+
+/*
+stringSettings returns the settings which can be set from strings, omitting
+those which can only be set by a tableSetter. It is used by loaders which only
+read strings, so that they do not offer settings that can never be set.
+*/
+func stringSettings(settings []Setting) []Setting {
+	strs := make([]Setting, 0, len(settings))
+	for i := range settings {
+		setter := settings[i].Setter
+		for ds, ok := setter.(*discardSetter); ok; ds, ok = setter.(*discardSetter) {
+			setter = ds.Setter
+		}
+		if _, ok := setter.(tableSetter); !ok {
+			strs = append(strs, settings[i])
+		}
+	}
+	return strs
+}
+
+/*
+structSliceSetter is a Setter for slices of structs, or of pointers to structs.
+
+Values can not be parsed from strings or other scalars. Instead, each table
+passed to setTable creates a new element, which is scanned and set from the
+table as if it were the root of a document. The paths of the element's
+settings are below the path of the slice, so errors refer to e.g.
+Servers->Port.
+*/
+type structSliceSetter struct {
+	append bool
+	slice  reflect.Value
+	reg    SetterRegistry
+}
+
+func (ss *structSliceSetter) String() string {
+	if ss.slice.Kind() == reflect.Invalid || ss.slice.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprint(ss.slice.Interface())
+}
+
+func (ss *structSliceSetter) Set(val string) error {
+	return &ConversionError{Value: val, ToType: ss.slice.Type()}
+}
+
+func (ss *structSliceSetter) SetInt(val int64) error {
+	return &ConversionError{Value: val, ToType: ss.slice.Type()}
+}
+
+func (ss *structSliceSetter) SetUint(val uint64) error {
+	return &ConversionError{Value: val, ToType: ss.slice.Type()}
+}
+
+func (ss *structSliceSetter) SetFloat(val float64) error {
+	return &ConversionError{Value: val, ToType: ss.slice.Type()}
+}
+
+func (ss *structSliceSetter) SetBool(val bool) error {
+	return &ConversionError{Value: val, ToType: ss.slice.Type()}
+}
+
+func (ss *structSliceSetter) Get() interface{} {
+	if ss.slice.Kind() == reflect.Invalid {
+		return nil
+	}
+	return ss.slice.Interface()
+}
+
+func (ss *structSliceSetter) setTable(path *Path, table map[string]interface{}) error {
+	elemType := ss.slice.Type().Elem()
+	t := elemType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Scan a new element using a separate configuration, so that its
+	// settings can be matched against the keys in table.
+	c := Config{reg: ss.reg}
+	root := &c.root
+	if path != nil {
+		root = path.parent.NewNodePath(path.name)
+	}
+	elem := reflect.New(t).Elem()
+	if err := c.scan(elem, root); err != nil {
+		return err
+	}
+	if err := newKeyIndex(c.settings).applyMap(pathKey(path), table); err != nil {
+		return err
+	}
+	c.setPtrs()
+	for elem.Type() != elemType {
+		ptr := reflect.New(elem.Type())
+		ptr.Elem().Set(elem)
+		elem = ptr
+	}
+
+	if ss.append {
+		ss.slice.Set(reflect.Append(ss.slice, elem))
+	} else {
+		ss.slice.Set(reflect.Append(ss.slice.Slice(0, 0), elem))
+		ss.append = true
+	}
+	return nil
+}
+
+/*
+newStructSliceSetter returns a Setter for val if it is a slice of structs or
+pointers to structs, or nil otherwise.
+
+Setters for the fields of each element are created using reg.
+*/
+func newStructSliceSetter(val reflect.Value, tag reflect.StructTag, reg SetterRegistry) Setter {
+	if val.Kind() != reflect.Slice {
+		return nil
+	}
+	t := val.Type().Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	append := true
+	if tagVal, ok := tag.Lookup("append"); ok {
+		append, _ = strconv.ParseBool(tagVal)
+	}
+	return &structSliceSetter{append: append, slice: val, reg: reg}
+}
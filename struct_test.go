@@ -0,0 +1,95 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStructSliceSetter(t *testing.T) {
+	type item struct {
+		Name  string
+		Count *int
+	}
+	t.Run("non-struct", func(t *testing.T) {
+		var val []int
+		s := newStructSliceSetter(reflect.ValueOf(&val).Elem(), "", DefaultSetterRegistry)
+		if s != nil {
+			t.Errorf("created setter %T for %T", s, val)
+		}
+	})
+	t.Run("Set", func(t *testing.T) {
+		var val []item
+		s := newStructSliceSetter(reflect.ValueOf(&val).Elem(), "", DefaultSetterRegistry)
+		if err := s.Set("x"); err == nil {
+			t.Error("setting a string did not return an error")
+		}
+		if err := s.SetInt(1); err == nil {
+			t.Error("setting an int did not return an error")
+		}
+	})
+	t.Run("setTable", func(t *testing.T) {
+		val := []item{{Name: "default"}}
+		s := newStructSliceSetter(reflect.ValueOf(&val).Elem(), "", DefaultSetterRegistry)
+		err := s.(tableSetter).setTable(nil, map[string]interface{}{
+			"name":  "a",
+			"count": 2,
+		})
+		if err != nil {
+			t.Errorf("setting table failed with error %s", err)
+		}
+		switch {
+		case len(val) != 2:
+			t.Errorf("unexpected value %v after setting table", val)
+		case val[1].Name != "a" || val[1].Count == nil || *val[1].Count != 2:
+			t.Errorf("unexpected value %v for new element", val[1])
+		}
+	})
+	t.Run("append false", func(t *testing.T) {
+		val := []*item{{Name: "default"}}
+		s := newStructSliceSetter(reflect.ValueOf(&val).Elem(), `append:"false"`, DefaultSetterRegistry)
+		for _, name := range []string{"a", "b"} {
+			err := s.(tableSetter).setTable(nil, map[string]interface{}{"name": name})
+			if err != nil {
+				t.Errorf("setting table failed with error %s", err)
+			}
+		}
+		switch {
+		case len(val) != 2:
+			t.Errorf("unexpected value %v after setting tables", val)
+		case val[0].Name != "a" || val[0].Count != nil || val[1].Name != "b":
+			t.Errorf("unexpected values %v, %v", *val[0], *val[1])
+		}
+	})
+	t.Run("error path", func(t *testing.T) {
+		type server struct {
+			Port int `max:"10"`
+		}
+		var val []server
+		root := NewRootPath("")
+		path := root.AddPath(root.NewPath("Servers"))
+		s := newStructSliceSetter(reflect.ValueOf(&val).Elem(), "", DefaultSetterRegistry)
+		err := s.(tableSetter).setTable(path, map[string]interface{}{"port": 11})
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v", err)
+		}
+		ve, ok := (*errs)[0].(*ValidationError)
+		if !ok || ve.Path.String() != "Servers->Port" {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+	t.Run("string loaders", func(t *testing.T) {
+		var val []item
+		settings := []Setting{{
+			Path:   NewRootPath("").NewPath("Items"),
+			Setter: newStructSliceSetter(reflect.ValueOf(&val).Elem(), "", DefaultSetterRegistry),
+		}}
+		for _, loader := range []Loader{new(EnvLoader), new(FlagLoader), new(DirLoader)} {
+			loader.Init(settings)
+			if usage := loader.Usage(); strings.Contains(strings.ToLower(usage), "items") {
+				t.Errorf("%s loader offers slice of structs in usage %q", loader.Name(), usage)
+			}
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/BurntSushi/toml"
+)
+
+/*
+TOMLDecoder implements a Decoder for TOML files.
+
+Tables, including those defined with dotted keys, correspond to the prefixes
+of nested structs, and keys to the names of individual settings, as described
+for FileLoader. For example,
+	[html_parser]
+	element_ids = ["head", "body"]
+	name = "parser"
+or, equivalently,
+	html_parser.element_ids = ["head", "body"]
+	html_parser.name = "parser"
+
+Integers, floats and booleans are set with the typed methods of Setter, and
+strings with Setter.Set. Datetimes are formatted using RFC 3339 and passed to
+Setter.Set, so they can be used to set time.Time values. Each element of an
+array is set in turn, so arrays are appended to slices. Arrays of tables can be
+used to set slices of structs, with each table appending a new element:
+	[[servers]]
+	host = "alpha"
+	[[servers]]
+	host = "beta"
+
+It is registered in DefaultDecoderRegistry for the ".toml" extension.
+*/
+type TOMLDecoder struct{}
+
+// Extensions returns the extensions for TOML files. It is always {".toml"}.
+func (TOMLDecoder) Extensions() []string {
+	return []string{".toml"}
+}
+
+/*
+Decode parses a TOML document.
+
+An error, if returned, will be of type *FileError.
+*/
+func (TOMLDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	var tree map[string]interface{}
+	if _, err := toml.Decode(string(data), &tree); err != nil {
+		fe := &FileError{Err: err}
+		if pe, ok := err.(toml.ParseError); ok {
+			fe.Line, fe.Column = pe.Position.Line, pe.Position.Col
+			fe.Err = errors.New(pe.Message)
+		}
+		return nil, fe
+	}
+	return tree, nil
+}
+
+func init() {
+	DefaultDecoderRegistry.Add(TOMLDecoder{})
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTOMLDecoder(t *testing.T) {
+	type server struct {
+		Host string
+		Port int `max:"65535"`
+	}
+	type options struct {
+		HTMLParser struct {
+			ElementIDs []string
+			Iterations uint8
+			Ratio      float32
+			Verbose    bool
+		}
+		Created time.Time `min:"2000-01-01"`
+		Servers []*server
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.toml", `
+created = 2019-03-27T10:00:00Z
+
+[html_parser]
+element-ids = ["head", "body"]
+iterations = 3
+ratio = 0.5
+verbose = true
+
+[[servers]]
+host = "alpha"
+port = 80
+
+[[servers]]
+host = "beta"
+`)
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		if err := c.Configure(&opts); err != nil {
+			t.Fatalf("unexpected error loading %s: %s", filename, err)
+		}
+		ids := opts.HTMLParser.ElementIDs
+		if len(ids) != 2 || ids[0] != "head" || ids[1] != "body" {
+			t.Errorf("unexpected value %s for element IDs", ids)
+		}
+		if opts.HTMLParser.Iterations != 3 {
+			t.Errorf("unexpected value %d for iterations", opts.HTMLParser.Iterations)
+		}
+		if opts.HTMLParser.Ratio != 0.5 {
+			t.Errorf("unexpected value %f for ratio", opts.HTMLParser.Ratio)
+		}
+		if !opts.HTMLParser.Verbose {
+			t.Error("verbose was not set")
+		}
+		if created := time.Date(2019, 3, 27, 10, 0, 0, 0, time.UTC); !opts.Created.Equal(created) {
+			t.Errorf("unexpected value %s for created", opts.Created)
+		}
+		switch {
+		case len(opts.Servers) != 2:
+			t.Errorf("unexpected value %v for servers", opts.Servers)
+		case opts.Servers[0].Host != "alpha" || opts.Servers[0].Port != 80:
+			t.Errorf("unexpected value %+v for first server", *opts.Servers[0])
+		case opts.Servers[1].Host != "beta" || opts.Servers[1].Port != 0:
+			t.Errorf("unexpected value %+v for second server", *opts.Servers[1])
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.toml", `
+created = 1999-12-31
+[[servers]]
+port = 65536
+`)
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		err := c.Configure(&opts)
+		if errs, ok := err.(*Errors); !ok || len(*errs) != 2 {
+			t.Errorf("unexpected error %v loading %s", err, filename)
+		}
+		if len(opts.Servers) != 0 {
+			t.Errorf("invalid server was added to %v", opts.Servers)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.toml", "a = 1\nb = \n")
+		defer cleanup()
+		var opts options
+		var c Config
+		c.SetLoaders(Loaders{&FileLoader{Filenames: []string{filename}}})
+		err := c.Configure(&opts)
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		if fe, ok := (*errs)[0].(*FileError); !ok || fe.Line != 2 {
+			t.Errorf("unexpected error %s", (*errs)[0])
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var keyReplacer = strings.NewReplacer("-", "", "_", "")
+
+/*
+normalizeKey returns a form of a document key that is suitable for matching
+against setting paths.
+
+Keys are lowercased, and any dashes or underscores are removed. This allows
+keys written as "html_parser", "html-parser" or "HTMLParser" to all match a
+path element named HTMLParser. Dots are preserved, so a key such as "auth.user"
+matches the nested path Auth->User.
+*/
+func normalizeKey(key string) string {
+	return strings.ToLower(keyReplacer.Replace(key))
+}
+
+// pathKey returns the normalized key for path, with elements joined by dots.
+func pathKey(path *Path) string {
+	elements := path.Elements()
+	for i := range elements {
+		elements[i] = normalizeKey(elements[i])
+	}
+	return strings.Join(elements, ".")
+}
+
+// displayKey returns a human-readable key for path, e.g. html_parser.name.
+func displayKey(path *Path) string {
+	return DottedNames.TransformName(path)
+}
+
+/*
+keyIndex maps normalized keys to settings.
+
+It is used by loaders that parse structured documents to match keys within the
+document to settings. A document is represented by a tree of values as
+described for Decoder.
+*/
+type keyIndex map[string]Setting
+
+/*
+newKeyIndex creates a keyIndex from settings.
+
+It panics if two settings have the same normalized key.
+*/
+func newKeyIndex(settings []Setting) keyIndex {
+	ki := make(keyIndex, len(settings))
+	for i := range settings {
+		key := pathKey(settings[i].Path)
+		if s, ok := ki[key]; ok {
+			panic(fmt.Sprintf(
+				"duplicate key %s for %s and %s", key, s.Path, settings[i].Path,
+			))
+		}
+		ki[key] = settings[i]
+	}
+	return ki
+}
+
+/*
+apply sets values from tree for each matching setting.
+
+Keys that do not match any setting are ignored. Nested tables may be of type
+map[string]string as well as map[string]interface{}. The returned error, if
+non-nil, will be of type Errors.
+*/
+func (ki keyIndex) apply(tree map[string]interface{}) error {
+	return ki.applyMap("", tree)
+}
+
+func (ki keyIndex) applyMap(prefix string, m map[string]interface{}) error {
+	// Sort keys so that values and errors are processed in a stable order.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs Errors
+	for _, k := range keys {
+		key := normalizeKey(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if setting, ok := ki[key]; ok {
+			if err := setValue(setting.Setter, setting.Path, m[k]); err != nil {
+				setErrorPath(err, setting.Path)
+				errs.Append(err)
+			}
+		} else {
+			var child map[string]interface{}
+			switch val := m[k].(type) {
+			case map[string]interface{}:
+				child = val
+			case map[string]string:
+				child = stringTree(val)
+			}
+			if err := ki.applyMap(key, child); err != nil {
+				errs.Append(err)
+			}
+		}
+	}
+	return errs.AsError()
+}
+
+// stringTree returns a tree of values with the same keys and values as m.
+func stringTree(m map[string]string) map[string]interface{} {
+	tree := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		tree[k] = v
+	}
+	return tree
+}
+
+/*
+setValue sets val using the most appropriate method of setter.
+
+Strings are passed to Set, while booleans and numbers are passed to SetBool,
+SetInt, SetUint or SetFloat. A time.Time is formatted using RFC 3339 and passed
+to Set. Each element of a []interface{} is set in turn, which appends to slice
+types. Tables, of type map[string]interface{}, can only be set if setter is a
+tableSetter, which is given path. A nil value is ignored.
+*/
+func setValue(setter Setter, path *Path, val interface{}) error {
+	switch val := val.(type) {
+	case nil:
+		return nil
+	case string:
+		return setter.Set(val)
+	case bool:
+		return setter.SetBool(val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return setter.SetInt(i)
+		}
+		if u, err := strconv.ParseUint(val.String(), 10, 64); err == nil {
+			return setter.SetUint(u)
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return &ConversionError{
+				Value: val, ToType: reflect.TypeOf(setter.Get()),
+			}
+		}
+		return setter.SetFloat(f)
+	case *Located:
+		// The file name is expected to be set by the caller.
+		if err := setValue(setter, path, val.Value); err != nil {
+			return &FileError{Line: val.Line, Column: val.Column, Err: err}
+		}
+		return nil
+	case time.Time:
+		return setter.Set(val.Format(time.RFC3339Nano))
+	case []interface{}:
+		var errs Errors
+		for i := range val {
+			if err := setValue(setter, path, val[i]); err != nil {
+				errs.Append(err)
+			}
+		}
+		return errs.AsError()
+	case map[string]interface{}:
+		if ts, ok := setter.(tableSetter); ok {
+			return ts.setTable(path, val)
+		}
+	case []map[string]interface{}:
+		var errs Errors
+		for i := range val {
+			if err := setValue(setter, path, val[i]); err != nil {
+				errs.Append(err)
+			}
+		}
+		return errs.AsError()
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setter.SetInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setter.SetUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return setter.SetFloat(v.Float())
+	}
+	return &ConversionError{Value: val, ToType: reflect.TypeOf(setter.Get())}
+}
+
+// setErrorPath sets path for any errors in err that do not already have one.
+func setErrorPath(err error, path *Path) {
+	switch err := err.(type) {
+	case *Errors:
+		for i := range *err {
+			setErrorPath((*err)[i], path)
+		}
+	case *FileError:
+		setErrorPath(err.Err, path)
+	case *ConversionError:
+		if err.Path == nil {
+			err.Path = path
+		}
+	case *ValidationError:
+		if err.Path == nil {
+			err.Path = path
+		}
+	}
+}
+
+/*
+keyUsage returns a usage string listing keys for settings, headed by title.
+
+Keys are given in the form returned by names, or by DottedNames if names is
+nil.
+*/
+func keyUsage(title string, settings []Setting, names NameTransformer) string {
+	if names == nil {
+		names = DottedNames
+	}
+	var b strings.Builder
+	b.WriteString(title)
+	b.WriteString(":\n")
+	for i := range settings {
+		b.WriteString("  ")
+		b.WriteString(names.TransformName(settings[i].Path))
+		b.WriteString("=")
+		b.WriteString(FriendlyTypeName(settings[i].Setter.Get()))
+		usage := strings.Replace(
+			settings[i].Tag.Get("help"), "\n", "\n    \t", -1,
+		)
+		z := isZeroValue(settings[i].Setter)
+		if usage != "" || !z {
+			b.WriteString("\n    \t")
+			b.WriteString(usage)
+			if !z {
+				b.WriteString(" (default ")
+				b.WriteString(settings[i].Setter.String())
+				b.WriteString(")")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+/*
+VaultLoader implements a Loader type to read secrets from the KV version 2
+secrets engine of HashiCorp Vault, or a compatible API.
+
+Only settings with a `vault` struct tag are set. The tag gives the API path of
+the secret, followed by "#" and the name of a field within the secret:
+	DBPassword string `vault:"secret/data/db#password" from:"vault"`
+Each secret is read once, however many settings refer to it. The `from` tag
+can be used as usual to prevent a setting from also being set by other
+loaders, such as by environment variables or command line flags.
+
+The loader authenticates with Token or, if it is empty, with the VAULT_TOKEN
+environment variable. If neither is set and RoleID is, it logs in with
+AppRole authentication instead.
+
+The zero value is ready to use, and reads secrets from the Vault server given
+by the VAULT_ADDR environment variable.
+*/
+type VaultLoader struct {
+	// Address is the base URL of the Vault server. If empty, the value of
+	// the VAULT_ADDR environment variable is used, or https://127.0.0.1:8200
+	// if that is not set.
+	Address string
+	// Token is the token used to authenticate with Vault.
+	Token string
+	// RoleID and SecretID are used for AppRole authentication if no token
+	// is set.
+	RoleID   string
+	SecretID string
+	// AppRolePath is the path at which the AppRole auth method is mounted.
+	// If empty, "approle" is used.
+	AppRolePath string
+	// Client is used to make requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Timeout limits the time taken by each request. If zero, a timeout of
+	// 10 seconds is used.
+	Timeout  time.Duration
+	settings []Setting
+	refs     map[*Path]vaultRef
+}
+
+// vaultRef is a reference to a field of a secret, parsed from a `vault` tag.
+type vaultRef struct {
+	path, field string
+}
+
+// Name returns the name of the loader. It is always "vault".
+func (*VaultLoader) Name() string {
+	return "vault"
+}
+
+/*
+Init initializes the loader with the given settings.
+
+Init must be called before Load. Settings without a `vault` tag are ignored.
+It panics if a `vault` tag does not contain a path and field separated by "#".
+*/
+func (vl *VaultLoader) Init(settings []Setting) {
+	vl.settings = nil
+	vl.refs = make(map[*Path]vaultRef)
+	for i := range settings {
+		tag, ok := settings[i].Tag.Lookup("vault")
+		if !ok {
+			continue
+		}
+		sep := strings.LastIndex(tag, "#")
+		if sep <= 0 || sep == len(tag)-1 {
+			panic(fmt.Sprintf(
+				"invalid vault tag %q for %s", tag, settings[i].Path,
+			))
+		}
+		vl.settings = append(vl.settings, settings[i])
+		vl.refs[settings[i].Path] = vaultRef{
+			path:  strings.Trim(tag[:sep], "/"),
+			field: tag[sep+1:],
+		}
+	}
+}
+
+/*
+Load attempts to read the secrets for the configured settings.
+
+The returned error, if non-nil, will be of type Errors. Each element will be an
+error from authenticating or reading a secret, an error for a field missing
+from a secret, or of type *ConversionError or *ValidationError.
+*/
+func (vl *VaultLoader) Load() error {
+	if len(vl.settings) == 0 {
+		return nil
+	}
+	token, err := vl.token()
+	if err != nil {
+		return &Errors{err}
+	}
+	var errs Errors
+	secrets := make(map[string]map[string]interface{})
+	for i := range vl.settings {
+		ref := vl.refs[vl.settings[i].Path]
+		secret, ok := secrets[ref.path]
+		if !ok {
+			var resp struct {
+				Data struct {
+					Data map[string]interface{} `json:"data"`
+				} `json:"data"`
+			}
+			if err := vl.request(http.MethodGet, ref.path, token, nil, &resp); err != nil {
+				errs.Append(err)
+			}
+			secret = resp.Data.Data
+			secrets[ref.path] = secret
+		}
+		if secret == nil {
+			continue
+		}
+		val, ok := secret[ref.field]
+		if !ok {
+			errs.Append(fmt.Errorf(
+				"vault: secret %s has no field %s for %s",
+				ref.path, ref.field, vl.settings[i].Path,
+			))
+			continue
+		}
+		if err := setValue(vl.settings[i].Setter, vl.settings[i].Path, val); err != nil {
+			setErrorPath(err, vl.settings[i].Path)
+			errs.Append(err)
+		}
+	}
+	return errs.AsError()
+}
+
+// token returns a token for authentication, logging in with AppRole if needed.
+func (vl *VaultLoader) token() (string, error) {
+	if vl.Token != "" {
+		return vl.Token, nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" || vl.RoleID == "" {
+		return token, nil
+	}
+	mount := vl.AppRolePath
+	if mount == "" {
+		mount = "approle"
+	}
+	body, err := json.Marshal(map[string]string{
+		"role_id":   vl.RoleID,
+		"secret_id": vl.SecretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	path := "auth/" + strings.Trim(mount, "/") + "/login"
+	if err := vl.request(http.MethodPost, path, "", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Auth.ClientToken, nil
+}
+
+// request makes a request to the Vault API, decoding the response into v.
+func (vl *VaultLoader) request(method, path, token string, body []byte, v interface{}) error {
+	addr := vl.Address
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if addr == "" {
+		addr = "https://127.0.0.1:8200"
+	}
+	url := strings.TrimRight(addr, "/") + "/v1/" + path
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	timeout := vl.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client := vl.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault: %s %s: %s", method, url, resp.Status)
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("vault: %s %s: %s", method, url, err)
+	}
+	return nil
+}
+
+// Usage returns a string with a list of secrets and their descriptions.
+func (vl *VaultLoader) Usage() string {
+	names := NameTransformerFunc(func(path *Path) string {
+		ref := vl.refs[path]
+		return ref.path + "#" + ref.field
+	})
+	return keyUsage("Vault Secrets", vl.settings, names)
+}
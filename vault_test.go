@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultLoader(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if r.Method != http.MethodPost || body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"auth": {"client_token": "approle-token"}}`))
+			return
+		}
+		if token := r.Header.Get("X-Vault-Token"); token != "token" && token != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"password": "pass1", "port": 5432}, "metadata": {"version": 1}}}`))
+	}))
+	defer server.Close()
+
+	root := NewRootPath("")
+	var password, user, name string
+	var port int
+	settings := settings{
+		{
+			Path: root.AddPath(root.NewPath("DBPassword")),
+			Tag:  `vault:"secret/data/db#password"`,
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&password).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("DBPort")),
+			Tag:  `vault:"/secret/data/db#port"`,
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&port).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Name")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&name).Elem(), "",
+			),
+		},
+	}
+
+	t.Run("token", func(t *testing.T) {
+		requests = 0
+		loader := &VaultLoader{Address: server.URL, Token: "token"}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if password != "pass1" || port != 5432 {
+			t.Errorf("unexpected values %q, %d", password, port)
+		}
+		if requests != 1 {
+			t.Errorf("unexpected number of requests %d", requests)
+		}
+		if usage := loader.Usage(); !strings.Contains(usage, "  secret/data/db#password=string\n") || strings.Contains(usage, "name") {
+			t.Errorf("unexpected usage %q", usage)
+		}
+	})
+
+	t.Run("approle", func(t *testing.T) {
+		var env env
+		defer env.Restore()
+		env.Set("VAULT_TOKEN", "")
+		password, port = "", 0
+		loader := &VaultLoader{Address: server.URL, RoleID: "role", SecretID: "secret"}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if password != "pass1" || port != 5432 {
+			t.Errorf("unexpected values %q, %d", password, port)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		loader := &VaultLoader{Address: server.URL, Token: "invalid"}
+		loader.Init(settings)
+		if err := loader.Load(); err == nil {
+			t.Error("invalid token did not return an error")
+		}
+
+		loader = &VaultLoader{Address: server.URL, Token: "token"}
+		loader.Init(append(settings[:0:0], Setting{
+			Path: root.NewPath("User"),
+			Tag:  `vault:"secret/data/db#user"`,
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&user).Elem(), "",
+			),
+		}))
+		err := loader.Load()
+		if errs, ok := err.(*Errors); !ok || len(*errs) != 1 {
+			t.Errorf("unexpected error %v for missing field", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer slow.Close()
+		loader := &VaultLoader{Address: slow.URL, Token: "token", Timeout: 10 * time.Millisecond}
+		loader.Init(settings)
+		if err := loader.Load(); err == nil {
+			t.Error("slow request did not return an error")
+		}
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("invalid tag did not panic")
+			}
+		}()
+		loader := new(VaultLoader)
+		loader.Init([]Setting{{Path: root.NewPath("X"), Tag: `vault:"secret/data/db"`}})
+	})
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+YAMLDecoder implements a Decoder for YAML files.
+
+The file must contain a single YAML document with a mapping at its root.
+Mappings correspond to the prefixes of nested structs, and keys to the names of
+individual settings, as described for FileLoader. For example,
+	html_parser:
+	  element_ids:
+	    - head
+	    - body
+	  name: parser
+
+Integer, float and boolean values are set with the typed methods of Setter,
+while strings, timestamps and other values are passed to Setter.Set. Each
+element of a sequence is set in turn, so sequences are appended to slices.
+Sequences of mappings can be used to set slices of structs. Null values are
+ignored. Anchors, aliases and merge keys are supported.
+
+Each value is wrapped in a *Located value, so errors setting a value will give
+the file name, line and column of the value, wrapping an error with the
+setting's Path.
+
+It is registered in DefaultDecoderRegistry for the ".yaml" and ".yml"
+extensions.
+*/
+type YAMLDecoder struct{}
+
+// Extensions returns the extensions for YAML files, {".yaml", ".yml"}.
+func (YAMLDecoder) Extensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+/*
+Decode parses a YAML document.
+
+An error, if returned, will be of type *FileError.
+*/
+func (YAMLDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	var doc yaml.Node
+	err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc)
+	if err != nil && err != io.EOF {
+		return nil, &FileError{Err: err}
+	}
+	if len(doc.Content) == 0 {
+		// An empty file, or one containing only comments, sets nothing.
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.ShortTag() == "!!null" {
+		// As does an empty document.
+		return nil, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, &FileError{
+			Line:   root.Line,
+			Column: root.Column,
+			Err:    errors.New("document root is not a mapping"),
+		}
+	}
+	tree, err := yamlTree(root)
+	if err != nil {
+		return nil, err
+	}
+	return tree.(map[string]interface{}), nil
+}
+
+/*
+yamlTree converts a yaml.Node to a tree of values as described for Decoder.
+
+Scalar values are wrapped in a *Located value with the position of the node.
+An error, if returned, will be of type *FileError.
+*/
+func yamlTree(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlTree(node.Alias)
+	case yaml.SequenceNode:
+		seq := make([]interface{}, len(node.Content))
+		for i := range node.Content {
+			val, err := yamlTree(node.Content[i])
+			if err != nil {
+				return nil, err
+			}
+			seq[i] = val
+		}
+		return seq, nil
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		var merge []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			if key.ShortTag() == "!!merge" {
+				merge = append(merge, val)
+				continue
+			}
+			if key.Kind != yaml.ScalarNode {
+				return nil, &FileError{
+					Line:   key.Line,
+					Column: key.Column,
+					Err:    errors.New("mapping key is not a scalar"),
+				}
+			}
+			v, err := yamlTree(val)
+			if err != nil {
+				return nil, err
+			}
+			m[key.Value] = v
+		}
+		// Merged values never override those set explicitly in the mapping.
+		for _, node := range merge {
+			for node.Kind == yaml.AliasNode {
+				node = node.Alias
+			}
+			nodes := []*yaml.Node{node}
+			if node.Kind == yaml.SequenceNode {
+				nodes = node.Content
+			}
+			for _, node := range nodes {
+				v, err := yamlTree(node)
+				if err != nil {
+					return nil, err
+				}
+				mm, ok := v.(map[string]interface{})
+				if !ok {
+					return nil, &FileError{
+						Line:   node.Line,
+						Column: node.Column,
+						Err:    errors.New("merged value is not a mapping"),
+					}
+				}
+				for k := range mm {
+					if _, ok := m[k]; !ok {
+						m[k] = mm[k]
+					}
+				}
+			}
+		}
+		return m, nil
+	}
+
+	loc := &Located{Line: node.Line, Column: node.Column}
+	switch node.ShortTag() {
+	case "!!null":
+	case "!!bool", "!!int", "!!float":
+		if err := node.Decode(&loc.Value); err != nil {
+			return nil, &FileError{Line: node.Line, Column: node.Column, Err: err}
+		}
+	default:
+		// Strings, timestamps and any other scalars are passed on as
+		// written, to be parsed by Setter.Set.
+		loc.Value = node.Value
+	}
+	return loc, nil
+}
+
+func init() {
+	DefaultDecoderRegistry.Add(YAMLDecoder{})
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestYAMLDecoder(t *testing.T) {
+	root := NewRootPath("")
+	node := root.AddNodePath(root.NewNodePath("HTMLParser"))
+	var duration []time.Duration
+	var iterations int
+	var name *string
+	var verbose bool
+	settings := settings{
+		{
+			Path: node.AddPath(node.NewPath("Durations")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&duration).Elem(), "",
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("Iterations")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&iterations).Elem(), `max:"10"`,
+			),
+		},
+		{
+			Path: node.AddPath(node.NewPath("ParserName")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&name).Elem(), "",
+			),
+		},
+		{
+			Path: root.AddPath(root.NewPath("Verbose")),
+			Setter: DefaultSetterRegistry.GetSetter(
+				reflect.ValueOf(&verbose).Elem(), "",
+			),
+		},
+	}
+
+	t.Run("load", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.yaml", `
+defaults: &defaults
+  iterations: 3
+html-parser:
+  <<: *defaults
+  durations:
+    - 1s
+    - 2
+  parser_name: mytest
+  unknown: {x: 1}
+Verbose: true
+`)
+		defer cleanup()
+		loader := &FileLoader{Filenames: []string{filename}}
+		loader.Init(settings)
+		if err := loader.Load(); err != nil {
+			t.Errorf("unexpected error loading %s: %s", filename, err)
+		}
+		if len(duration) != 2 || duration[0] != time.Second || duration[1] != 2*time.Second {
+			t.Errorf("unexpected value %s for durations", duration)
+		}
+		if iterations != 3 {
+			t.Errorf("unexpected value %d for iterations", iterations)
+		}
+		if name == nil || *name != "mytest" {
+			t.Errorf("unexpected value %v for name", name)
+		}
+		if !verbose {
+			t.Error("verbose was not set")
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		filename, cleanup := writeTempFile(t, "config.yaml", "HTMLParser:\n  Iterations: 11\n")
+		defer cleanup()
+		loader := &FileLoader{Filenames: []string{filename}}
+		loader.Init(settings)
+		err := loader.Load()
+		errs, ok := err.(*Errors)
+		if !ok || len(*errs) != 1 {
+			t.Fatalf("unexpected error %v loading %s", err, filename)
+		}
+		fe, ok := (*errs)[0].(*FileError)
+		if !ok {
+			t.Fatalf("unexpected error %s", (*errs)[0])
+		}
+		if fe.Filename != filename || fe.Line != 2 || fe.Column != 15 {
+			t.Errorf("unexpected location in error %s", fe)
+		}
+		if ve, ok := fe.Err.(*ValidationError); !ok || ve.Path != settings[1].Path {
+			t.Errorf("unexpected error %s", fe.Err)
+		}
+		if iterations != 3 {
+			t.Errorf("unexpected value %d for iterations", iterations)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		if _, err := (YAMLDecoder{}).Decode([]byte("a: [b\n")); err == nil {
+			t.Errorf("decoding invalid YAML did not return an error")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		for _, data := range []string{"", "# comment\n", "---\n"} {
+			m, err := YAMLDecoder{}.Decode([]byte(data))
+			if err != nil || len(m) != 0 {
+				t.Errorf("decoding %q returned %v, %v", data, m, err)
+			}
+		}
+	})
+
+	t.Run("not a mapping", func(t *testing.T) {
+		_, err := YAMLDecoder{}.Decode([]byte("- a\n- b\n"))
+		if fe, ok := err.(*FileError); !ok || fe.Line != 1 {
+			t.Errorf("decoding a sequence returned error %v", err)
+		}
+	})
+}